@@ -1,48 +1,351 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/chkp-roniz/gh-copilot-insights/src/api"
+	"github.com/chkp-roniz/gh-copilot-insights/src/doctor"
+	"github.com/chkp-roniz/gh-copilot-insights/src/fake"
+	"github.com/chkp-roniz/gh-copilot-insights/src/seats"
 	"github.com/chkp-roniz/gh-copilot-insights/src/usage"
 	logger "github.com/sirupsen/logrus"
 	easy "github.com/t-tomalak/logrus-easy-formatter"
 )
 
 func main() {
-	scope := flag.String("scope", "", "The name of the organization or enterprise for which to retrieve insights")
-	output := flag.String("output", "json", "The output format, either 'json', 'summary', or 'table'")
-	extended := flag.Bool("extended", false, "Include extended metrics in the output")
-	debug := flag.Bool("debug", false, "Enable debug mode")
-	flag.Parse()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "seats":
+			runSeats(os.Args[2:])
+			return
+		case "reclaim":
+			runReclaim(os.Args[2:])
+			return
+		case "assign":
+			runAssign(os.Args[2:])
+			return
+		case "fake-server":
+			runFakeServer(os.Args[2:])
+			return
+		case "generate":
+			runGenerate(os.Args[2:])
+			return
+		case "doctor":
+			runDoctor(os.Args[2:])
+			return
+		}
+	}
+	runInsights(os.Args[1:])
+}
+
+// Exit codes of the failures users can fix, so scripts can tell them apart
+const (
+	exitError = 1
+	// exitUsage matches the exit code of the flag package for unknown flags
+	exitUsage            = 2
+	exitScopeNotFound    = 3
+	exitPermission       = 4
+	exitMetricsDisabled  = 5
+	exitInsufficientData = 6
+	exitRateLimited      = 7
+)
+
+// remediation explains how to fix the typed errors of the api package and picks their exit code, ok is false
+// for other errors
+func remediation(err error) (advice string, code int, ok bool) {
+	var (
+		scopeErr      *api.ScopeResolutionError
+		notFoundErr   *api.ScopeNotFoundError
+		permissionErr *api.PermissionError
+		disabledErr   *api.MetricsDisabledError
+		dataErr       *api.InsufficientDataError
+		rateLimitErr  *api.RateLimitError
+	)
+	switch {
+	case errors.As(err, &rateLimitErr):
+		return "Wait for the rate limit to reset, or fetch fewer scopes per run.", exitRateLimited, true
+	case errors.As(err, &disabledErr):
+		return "An owner must enable the \"Copilot metrics API access\" policy in the Copilot settings of the organization or enterprise.", exitMetricsDisabled, true
+	case errors.As(err, &permissionErr):
+		if permissionErr.StatusCode == http.StatusUnauthorized {
+			return fmt.Sprintf("The token was rejected, log in again with: gh auth login -h %s", api.Hostname()), exitPermission, true
+		}
+		// Any one of the accepted scopes is enough
+		scopes := permissionErr.Missing()
+		if len(scopes) == 0 {
+			scopes = []string{"manage_billing:copilot"}
+		}
+		return fmt.Sprintf("Grant the token one of the scopes %s, e.g. with: gh auth refresh -h %s -s %s\n"+
+			"The Copilot endpoints also require an owner or billing manager of the organization or enterprise.",
+			strings.Join(scopes, ", "), api.Hostname(), scopes[0]), exitPermission, true
+	case errors.As(err, &dataErr):
+		return "GitHub only reports metrics for days with five or more active Copilot users, try a larger scope or a longer window.", exitInsufficientData, true
+	case errors.As(err, &scopeErr), errors.As(err, &notFoundErr):
+		return "Check the spelling of --scope and --team and that the token can see them.", exitScopeNotFound, true
+	}
+	return "", exitError, false
+}
+
+// exitWithError reports a failed command. Errors with a known cause are printed in full together with how to
+// fix them, network and server failures that a retry may fix print message, and any other error is printed as is.
+func exitWithError(message string, fields logger.Fields, err error) {
+	logger.WithFields(fields).Debugf("Error: %v", err)
+	if advice, code, ok := remediation(err); ok {
+		fmt.Printf("Error: %v\n%s\n", err, advice)
+		os.Exit(code)
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Println("Error: the run exceeded --timeout and was cancelled.")
+	case errors.Is(err, context.Canceled):
+		fmt.Println("Error: the run was interrupted.")
+	case api.IsTransient(err):
+		fmt.Println(message)
+	default:
+		fmt.Printf("Error: %v\n", err)
+	}
+	os.Exit(exitError)
+}
+
+// newContext cancels the run on interrupt or once timeout elapses, a zero timeout means no limit
+func newContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// applyScopeType registers an explicit --scope-type, a team scope may be written as <org>/<team> or
+// <enterprise>/<team>
+func applyScopeType(scopeType string, scope, team *string) error {
+	if scopeType == "" {
+		return nil
+	}
+	if scopeType == "team" {
+		if team == nil {
+			return fmt.Errorf("scope type 'team' is not supported by this command")
+		}
+		if i := strings.Index(*scope, "/"); i >= 0 {
+			*scope, *team = (*scope)[:i], (*scope)[i+1:]
+		}
+		if *team == "" {
+			return fmt.Errorf("scope type 'team' requires --scope <org>/<team>, --scope <enterprise>/<team> or --team")
+		}
+		// The parent may be an organization or an enterprise, so its type is detected like any other scope
+		return nil
+	}
+	return api.SetScopeType(*scope, scopeType)
+}
+
+// parseScopes splits a comma-separated list of scopes, @file reads them from a file with one or more per line
+func parseScopes(value string) ([]string, error) {
+	if strings.HasPrefix(value, "@") {
+		data, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				lines = append(lines, line)
+			}
+		}
+		value = strings.Join(lines, ",")
+	}
+
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, scope := range strings.Split(value, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope != "" && !seen[strings.ToLower(scope)] {
+			seen[strings.ToLower(scope)] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("no scopes given")
+	}
+	return scopes, nil
+}
+
+func enableDebug() {
+	logger.SetLevel(logger.DebugLevel)
+	logger.SetFormatter(&easy.Formatter{
+		TimestampFormat: "2006-01-02 15:04:05",
+		LogFormat:       "%time% [%lvl%]: %msg%\n",
+	})
+	logger.SetOutput(os.Stdout)
+	logger.Debug("Debug mode enabled")
+}
+
+func runInsights(args []string) {
+	flags := flag.NewFlagSet("copilot-insights", flag.ExitOnError)
+	scope := flags.String("scope", "", "The organization or enterprise for which to retrieve insights, a comma-separated list, or @file listing one per line")
+	scopeType := flags.String("scope-type", "", "The type of the scope, either 'org', 'enterprise', or 'team', detected automatically when omitted")
+	team := flags.String("team", "", "The slug of a team within the organization or enterprise for which to retrieve insights")
+	since := flags.String("since", "", "Only include days on or after this date (YYYY-MM-DD)")
+	until := flags.String("until", "", "Only include days on or before this date (YYYY-MM-DD)")
+	output := flags.String("output", "json", "The output format, either 'json', 'summary', or 'table'")
+	fromDir := flags.String("from-dir", "", "Compute the insights offline from usage.json, metrics.json and billing.json exported to this directory")
+	record := flags.String("record", "", "Save every raw API response to this directory")
+	replay := flags.String("replay", "", "Re-run the analysis from the API responses recorded to this directory, without network")
+	breakdown := flags.String("breakdown", "", "Break an enterprise scope down by 'orgs', adding one insight per organization and a ranking")
+	extended := flags.Bool("extended", false, "Include extended metrics in the output")
+	series := flags.Bool("series", false, "Add the metrics of every day and ISO week to the output")
+	compare := flags.Bool("compare", false, "Compare every metric with the previous window of the same length")
+	languages := flags.String("languages", "", "Break the code completions down by language, either 'all' or a comma-separated list of languages")
+	sortLanguages := flags.String("sort-languages", api.SortLanguagesByUsers, "The order of the language breakdown, either 'users', 'acceptance', 'efficiency', 'suggestions', or 'name'")
+	host := flags.String("hostname", "", "The GitHub Enterprise Server or GHE.com host to query, defaults to GH_HOST or the gh default host")
+	timeout := flags.Duration("timeout", 0, "Cancel the run if it takes longer than this, e.g. '2m' (no limit by default)")
+	debug := flags.Bool("debug", false, "Enable debug mode")
+	flags.Parse(args)
+
+	ctx, cancel := newContext(*timeout)
+	defer cancel()
+
+	if *host != "" {
+		api.SetHostname(*host)
+	}
+	options := api.InsightOptions{Series: *series}
+	if *languages != "" {
+		var languageList []string
+		if *languages != "all" {
+			languageList = splitList(*languages)
+		}
+		var err error
+		if options.Languages, err = api.NewLanguageBreakdown(languageList, *sortLanguages); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitUsage)
+		}
+	}
 
 	if *debug {
-		logger.SetLevel(logger.DebugLevel)
-		logger.SetFormatter(&easy.Formatter{
-			TimestampFormat: "2006-01-02 15:04:05",
-			LogFormat:       "%time% [%lvl%]: %msg%\n",
-		})
-		logger.SetOutput(os.Stdout)
-		logger.Debug("Debug mode enabled")
-		logger.Debugf("Scope: %s, Output: %s, Extended: %v", *scope, *output, *extended)
+		enableDebug()
+		logger.Debugf("Scope: %s, Team: %s, Output: %s, Extended: %v", *scope, *team, *output, *extended)
 	}
 
+	if *scope == "" && *fromDir != "" {
+		*scope = filepath.Base(filepath.Clean(*fromDir))
+	}
 	if *scope == "" {
 		fmt.Println("Error: --scope is required")
-		flag.Usage()
-		os.Exit(1)
+		flags.Usage()
+		os.Exit(exitUsage)
+	}
+	if *output != "json" && *output != "summary" && *output != "table" {
+		fmt.Println("Invalid output format. Use 'json', 'summary', or 'table'.")
+		os.Exit(exitUsage)
+	}
+
+	scopes, err := parseScopes(*scope)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		// An unreadable @file is not a usage error
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			os.Exit(exitError)
+		}
+		os.Exit(exitUsage)
+	}
+	if len(scopes) == 1 {
+		err = applyScopeType(*scopeType, &scopes[0], team)
+	} else if *scopeType == "team" {
+		err = fmt.Errorf("scope type 'team' requires a single scope, use --team with several scopes")
+	} else {
+		for i := range scopes {
+			if err = applyScopeType(*scopeType, &scopes[i], nil); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	window, err := api.ParseDateRange(*since, *until)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	if *record != "" && *replay != "" {
+		fmt.Println("Error: --record and --replay can't be combined")
+		os.Exit(exitUsage)
+	}
+	switch *breakdown {
+	case "":
+	case "orgs":
+		if len(scopes) > 1 || *team != "" || *scopeType == "org" {
+			fmt.Println("Error: --breakdown requires a single enterprise scope without --team")
+			os.Exit(exitUsage)
+		}
+		if *fromDir != "" {
+			fmt.Println("Error: --breakdown can't be combined with --from-dir, the exports don't list the organizations of an enterprise")
+			os.Exit(exitUsage)
+		}
+	default:
+		fmt.Println("Invalid breakdown. Use 'orgs'.")
+		os.Exit(exitUsage)
+	}
+	api.SetRecordDir(*record)
+	api.SetReplayDir(*replay)
+
+	var source api.DataSource
+	if *fromDir != "" {
+		source = api.NewFileSource(*fromDir, len(scopes) == 1)
+	} else if source, err = api.NewGitHubSource(); err != nil {
+		exitWithError("Error fetching Copilot insights. Please try again.", logger.Fields{"scope": *scope}, err)
 	}
 
 	// Fetch Copilot usage insights
-	usageData, err := api.FetchCopilotUsage(*scope)
+	var fetch func(window api.DateRange) ([]api.Insight, error)
+	switch *breakdown {
+	case "":
+		fetch = func(window api.DateRange) ([]api.Insight, error) {
+			return api.FetchCopilotUsage(ctx, source, scopes, *team, window, options)
+		}
+	case "orgs":
+		fetch = func(window api.DateRange) ([]api.Insight, error) {
+			return api.FetchEnterpriseBreakdown(ctx, source, scopes[0], window, options)
+		}
+	}
+	usageData, err := fetch(window)
 	if err != nil {
-		logger.WithFields(logger.Fields{
-			"scope": *scope,
-		}).Debugf("Error: %v", err)
-		fmt.Println("Error fetching Copilot insights. Please try again.")
-		os.Exit(1)
+		exitWithError("Error fetching Copilot insights. Please try again.", logger.Fields{"scope": *scope, "team": *team}, err)
+	}
+
+	if *compare {
+		// Without --since and --until the window is the one the data covers, the first insight's is used for all
+		previousWindow, err := usageData[0].Window.Previous()
+		if err == nil {
+			logger.Debugf("Comparing with %s to %s", previousWindow.Since, previousWindow.Until)
+			var previous []api.Insight
+			if previous, err = fetch(previousWindow); err == nil {
+				api.Compare(usageData, previous)
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				exitWithError("Error fetching Copilot insights. Please try again.", logger.Fields{"scope": *scope, "team": *team}, err)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: no comparison, the previous window could not be loaded: %v\n", err)
+		}
 	}
 
 	// Print output
@@ -53,10 +356,460 @@ func main() {
 		usage.PrintSummary(usageData, *extended)
 	case "table":
 		usage.PrintTable(usageData, *extended)
-	default:
-		fmt.Println("Invalid output format. Use 'json', 'summary', or 'table'.")
+	}
+	if *breakdown != "" && *output != "json" {
+		// The enterprise total comes first, only its organizations are ranked
+		fmt.Println()
+		usage.PrintRanking(usageData[1:])
+	}
+
+	logger.Debug("Execution completed")
+}
+
+func runSeats(args []string) {
+	flags := flag.NewFlagSet("copilot-insights seats", flag.ExitOnError)
+	scope := flags.String("scope", "", "The name of the organization or enterprise whose seats to list")
+	scopeType := flags.String("scope-type", "", "The type of the scope, only 'org' as seats are managed in organizations, detected automatically when omitted")
+	inactiveDays := flags.Int("inactive-days", 0, "Only list seats without activity for at least this many days")
+	output := flags.String("output", "table", "The output format, either 'json' or 'table'")
+	host := flags.String("hostname", "", "The GitHub Enterprise Server or GHE.com host to query, defaults to GH_HOST or the gh default host")
+	timeout := flags.Duration("timeout", 0, "Cancel the run if it takes longer than this, e.g. '2m' (no limit by default)")
+	debug := flags.Bool("debug", false, "Enable debug mode")
+	flags.Parse(args)
+
+	ctx, cancel := newContext(*timeout)
+	defer cancel()
+
+	if *host != "" {
+		api.SetHostname(*host)
+	}
+
+	if *debug {
+		enableDebug()
+		logger.Debugf("Scope: %s, Inactive days: %d, Output: %s", *scope, *inactiveDays, *output)
+	}
+
+	if *scope == "" {
+		fmt.Println("Error: --scope is required")
+		flags.Usage()
+		os.Exit(exitUsage)
+	}
+	if *output != "json" && *output != "table" {
+		fmt.Println("Invalid output format. Use 'json' or 'table'.")
+		os.Exit(exitUsage)
+	}
+
+	if err := applyScopeType(*scopeType, scope, nil); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	seatList, err := api.FetchCopilotSeats(ctx, *scope)
+	if err != nil {
+		exitWithError("Error fetching Copilot seats. Please try again.", logger.Fields{"scope": *scope}, err)
+	}
+
+	activities := api.GetSeatActivity(seatList, *inactiveDays, time.Now())
+
+	switch *output {
+	case "json":
+		seats.PrintJSON(activities)
+	case "table":
+		seats.PrintTable(activities)
+	}
+
+	logger.Debug("Execution completed")
+}
+
+func confirm(prompt, expected string) bool {
+	fmt.Printf("%s Type '%s' to confirm: ", prompt, expected)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(answer) == expected
+}
+
+// requireOrganization exits with a usage error when the scope of a seat management command is an enterprise
+func requireOrganization(ctx context.Context, scope string) {
+	err := api.RequireOrganization(ctx, scope)
+	var orgErr *api.NotOrganizationError
+	if errors.As(err, &orgErr) {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if err != nil {
+		exitWithError("Error resolving the scope. Please try again.", logger.Fields{"scope": scope}, err)
+	}
+}
+
+func runReclaim(args []string) {
+	flags := flag.NewFlagSet("copilot-insights reclaim", flag.ExitOnError)
+	scope := flags.String("scope", "", "The name of the organization whose seats to reclaim")
+	scopeType := flags.String("scope-type", "", "The type of the scope, only 'org' as seats are managed in organizations, detected automatically when omitted")
+	inactiveDays := flags.Int("inactive-days", 90, "Reclaim seats without activity for at least this many days, 0 to disable")
+	neverActiveDays := flags.Int("never-active-days", 30, "Reclaim seats never used and assigned at least this many days ago, 0 to disable")
+	pendingDays := flags.Int("pending-days", 30, "Reclaim seats of users whose organization invitation is pending for at least this many days, 0 to disable")
+	allowList := flags.String("allow-list", "", "A file of protected logins, one per line, whose seats are never reclaimed")
+	apply := flags.Bool("apply", false, "Remove the planned seats instead of only printing the plan")
+	yes := flags.Bool("yes", false, "Skip the interactive confirmation when applying")
+	auditLog := flags.String("audit-log", "copilot-reclaim-audit.log", "The file to which removed seats are appended")
+	output := flags.String("output", "table", "The output format, either 'json' or 'table'")
+	host := flags.String("hostname", "", "The GitHub Enterprise Server or GHE.com host to query, defaults to GH_HOST or the gh default host")
+	timeout := flags.Duration("timeout", 0, "Cancel the run if it takes longer than this, e.g. '2m' (no limit by default)")
+	debug := flags.Bool("debug", false, "Enable debug mode")
+	flags.Parse(args)
+
+	ctx, cancel := newContext(*timeout)
+	defer cancel()
+
+	if *host != "" {
+		api.SetHostname(*host)
+	}
+
+	if *debug {
+		enableDebug()
+		logger.Debugf("Scope: %s, Inactive days: %d, Never active days: %d, Pending days: %d, Apply: %v", *scope, *inactiveDays, *neverActiveDays, *pendingDays, *apply)
+	}
+
+	if *scope == "" {
+		fmt.Println("Error: --scope is required")
+		flags.Usage()
+		os.Exit(exitUsage)
+	}
+
+	if *output != "json" && *output != "table" {
+		fmt.Println("Invalid output format. Use 'json' or 'table'.")
+		os.Exit(exitUsage)
+	}
+
+	if err := applyScopeType(*scopeType, scope, nil); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	requireOrganization(ctx, *scope)
+
+	protected, err := seats.ReadAllowList(*allowList)
+	if err != nil {
+		fmt.Printf("Error reading allow list: %v\n", err)
+		os.Exit(1)
+	}
+
+	seatList, err := api.FetchCopilotSeats(ctx, *scope)
+	if err != nil {
+		exitWithError("Error fetching Copilot seats. Please try again.", logger.Fields{"scope": *scope}, err)
+	}
+
+	var invitations []api.Invitation
+	if *pendingDays > 0 {
+		invitations, err = api.FetchPendingInvitations(ctx, *scope)
+		if err != nil {
+			exitWithError("Error fetching pending invitations. Please try again.", logger.Fields{"scope": *scope}, err)
+		}
+	}
+
+	policy := api.ReclaimPolicy{
+		InactiveDays:          *inactiveDays,
+		NeverActiveDays:       *neverActiveDays,
+		PendingInvitationDays: *pendingDays,
+		Protected:             protected,
+	}
+	plan := api.PlanReclamation(*scope, seatList, invitations, policy, time.Now())
+
+	switch *output {
+	case "json":
+		seats.PrintPlanJSON(plan)
+	case "table":
+		seats.PrintPlanTable(plan)
+	}
+
+	if !*apply {
+		// Printed to stderr so the JSON plan can still be piped
+		fmt.Fprintln(os.Stderr, "Dry run, no seats were removed. Re-run with --apply to remove them.")
+		return
+	}
+	if len(plan.Remove) == 0 {
+		fmt.Println("No seats to remove.")
+		return
+	}
+	if !*yes && !confirm(fmt.Sprintf("This removes %d Copilot seats from %s.", len(plan.Remove), *scope), *scope) {
+		fmt.Println("Aborted, no seats were removed.")
 		os.Exit(1)
 	}
 
+	cancelled, err := api.CancelCopilotSeats(ctx, *scope, plan.Logins())
+	result := "removed"
+	switch {
+	case err != nil:
+		result = fmt.Sprintf("failed: %v", err)
+	case cancelled != len(plan.Remove):
+		// GitHub only reports a count, so no single seat is known to be removed
+		result = "unconfirmed"
+	}
+	if auditErr := seats.WriteAuditLog(*auditLog, plan, result, cancelled, time.Now()); auditErr != nil {
+		fmt.Printf("Error writing audit log: %v\n", auditErr)
+	}
+	if err != nil {
+		exitWithError("Error removing Copilot seats. Please try again.", logger.Fields{"scope": *scope}, err)
+	}
+
+	if cancelled != len(plan.Remove) {
+		fmt.Printf("Warning: GitHub cancelled %d of the %d requested Copilot seats, check the seats of %s and see %s for details.\n", cancelled, len(plan.Remove), *scope, *auditLog)
+		return
+	}
+	fmt.Printf("Removed %d Copilot seats, see %s for details.\n", cancelled, *auditLog)
 	logger.Debug("Execution completed")
 }
+
+func runAssign(args []string) {
+	flags := flag.NewFlagSet("copilot-insights assign", flag.ExitOnError)
+	scope := flags.String("scope", "", "The name of the organization in which to assign seats")
+	scopeType := flags.String("scope-type", "", "The type of the scope, only 'org' as seats are managed in organizations, detected automatically when omitted")
+	file := flags.String("file", "", "A CSV or text file listing one login or team (team:<slug>) per line")
+	seatPrice := flags.Float64("seat-price", 19, "The monthly price of a seat, used to project the cost change")
+	apply := flags.Bool("apply", false, "Assign the seats instead of only printing the plan")
+	yes := flags.Bool("yes", false, "Skip the interactive confirmation when applying")
+	output := flags.String("output", "table", "The output format, either 'json' or 'table'")
+	host := flags.String("hostname", "", "The GitHub Enterprise Server or GHE.com host to query, defaults to GH_HOST or the gh default host")
+	timeout := flags.Duration("timeout", 0, "Cancel the run if it takes longer than this, e.g. '2m' (no limit by default)")
+	debug := flags.Bool("debug", false, "Enable debug mode")
+	flags.Parse(args)
+
+	ctx, cancel := newContext(*timeout)
+	defer cancel()
+
+	if *host != "" {
+		api.SetHostname(*host)
+	}
+
+	if *debug {
+		enableDebug()
+		logger.Debugf("Scope: %s, File: %s, Apply: %v", *scope, *file, *apply)
+	}
+
+	if *scope == "" || *file == "" {
+		fmt.Println("Error: --scope and --file are required")
+		flags.Usage()
+		os.Exit(exitUsage)
+	}
+
+	if err := applyScopeType(*scopeType, scope, nil); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+	if *output != "json" && *output != "table" {
+		fmt.Println("Invalid output format. Use 'json' or 'table'.")
+		os.Exit(exitUsage)
+	}
+
+	requireOrganization(ctx, *scope)
+
+	assignments, err := seats.ReadAssignmentList(*file, *scope)
+	if err != nil {
+		fmt.Printf("Error reading %s: %v\n", *file, err)
+		os.Exit(1)
+	}
+
+	billing, err := api.FetchCopilotBilling(ctx, *scope)
+	if err != nil {
+		exitWithError("Error fetching Copilot seats. Please try again.", logger.Fields{"scope": *scope}, err)
+	}
+
+	plan, err := api.PlanSeatAssignment(ctx, *scope, assignments, billing)
+	if err != nil {
+		exitWithError("Error checking seat assignments. Please try again.", logger.Fields{"scope": *scope}, err)
+	}
+
+	if *apply && plan.Pending() > 0 {
+		seats.PrintAssignmentTable(plan, *seatPrice)
+		if !*yes && !confirm(fmt.Sprintf("This adds %d Copilot seats to %s.", plan.ProjectedSeats-plan.CurrentSeats, *scope), *scope) {
+			fmt.Println("Aborted, no seats were assigned.")
+			os.Exit(1)
+		}
+
+		plan, err = api.ApplySeatAssignment(ctx, plan)
+		if err != nil {
+			exitWithError("Error assigning Copilot seats. Please try again.", logger.Fields{"scope": *scope}, err)
+		}
+	}
+
+	switch *output {
+	case "json":
+		seats.PrintAssignmentJSON(plan)
+	case "table":
+		seats.PrintAssignmentTable(plan, *seatPrice)
+	}
+
+	if !*apply {
+		fmt.Fprintln(os.Stderr, "Dry run, no seats were assigned. Re-run with --apply to assign them.")
+	}
+	logger.Debug("Execution completed")
+}
+
+func runDoctor(args []string) {
+	flags := flag.NewFlagSet("copilot-insights doctor", flag.ExitOnError)
+	scope := flags.String("scope", "", "The organization or enterprise whose Copilot endpoints to check (optional)")
+	team := flags.String("team", "", "The slug of a team within the scope whose endpoints to check instead")
+	output := flags.String("output", "checklist", "The output format, either 'json' or 'checklist'")
+	host := flags.String("hostname", "", "The GitHub Enterprise Server or GHE.com host to check, defaults to GH_HOST or the gh default host")
+	timeout := flags.Duration("timeout", 0, "Cancel the run if it takes longer than this, e.g. '2m' (no limit by default)")
+	debug := flags.Bool("debug", false, "Enable debug mode")
+	flags.Parse(args)
+
+	ctx, cancel := newContext(*timeout)
+	defer cancel()
+
+	if *host != "" {
+		api.SetHostname(*host)
+	}
+
+	if *debug {
+		enableDebug()
+		logger.Debugf("Scope: %s, Team: %s, Output: %s", *scope, *team, *output)
+	}
+
+	if *team != "" && *scope == "" {
+		fmt.Println("Error: --team requires --scope")
+		os.Exit(exitUsage)
+	}
+	if *output != "json" && *output != "checklist" {
+		fmt.Println("Invalid output format. Please use 'json' or 'checklist'.")
+		os.Exit(exitUsage)
+	}
+
+	checks := api.Diagnose(ctx, *scope, *team)
+
+	switch *output {
+	case "json":
+		doctor.PrintJSON(checks)
+	case "checklist":
+		doctor.PrintChecklist(checks)
+	}
+
+	if !api.Healthy(checks) {
+		os.Exit(exitError)
+	}
+}
+
+// splitList splits a comma-separated flag value, ignoring blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// generatorFlags registers the flags shaping generated data, the returned function builds the config once
+// the flags are parsed
+func generatorFlags(flags *flag.FlagSet) func() (fake.Config, error) {
+	defaults := fake.DefaultConfig()
+	users := flags.Int("users", defaults.Users, "The number of users of each generated organization or enterprise")
+	days := flags.Int("days", defaults.Days, "The number of days of generated data")
+	end := flags.String("end", "", "The last generated day (YYYY-MM-DD), today when omitted")
+	seed := flags.Int64("seed", defaults.Seed, "The seed of the generated data")
+	editors := flags.String("editors", strings.Join(defaults.Editors, ","), "A comma-separated list of editors users pick from")
+	languages := flags.String("languages", strings.Join(defaults.Languages, ","), "A comma-separated list of languages users work in")
+	models := flags.String("models", strings.Join(defaults.Models, ","), "A comma-separated list of models, the first is the default model and the others are custom models")
+	teams := flags.String("teams", "", "A comma-separated list of teams that half of the seats are assigned through")
+	acceptance := flags.Float64("acceptance", defaults.AcceptanceRate, "The mean acceptance rate of suggestions, between 0 and 1")
+	acceptanceSpread := flags.Float64("acceptance-spread", defaults.AcceptanceSpread, "The standard deviation of the acceptance rate across users")
+	growth := flags.String("growth", defaults.Growth, "The adoption curve over the days, either 'flat', 'linear', or 'logistic'")
+	initialAdoption := flags.Float64("initial-adoption", -1, "The share of users that adopted Copilot on the first day, --adoption when omitted")
+	adoption := flags.Float64("adoption", defaults.Adoption, "The share of users that adopted Copilot by the last day")
+
+	return func() (fake.Config, error) {
+		config := fake.Config{
+			Users:            *users,
+			Days:             *days,
+			End:              time.Now(),
+			Seed:             *seed,
+			Editors:          splitList(*editors),
+			Languages:        splitList(*languages),
+			Models:           splitList(*models),
+			Teams:            splitList(*teams),
+			AcceptanceRate:   *acceptance,
+			AcceptanceSpread: *acceptanceSpread,
+			Growth:           *growth,
+			InitialAdoption:  *initialAdoption,
+			Adoption:         *adoption,
+		}
+		if config.InitialAdoption < 0 {
+			config.InitialAdoption = config.Adoption
+		}
+		if *end != "" {
+			date, err := time.Parse("2006-01-02", *end)
+			if err != nil {
+				return config, fmt.Errorf("invalid --end date %q, expected YYYY-MM-DD", *end)
+			}
+			config.End = date
+		}
+		return config, config.Validate()
+	}
+}
+
+func runFakeServer(args []string) {
+	flags := flag.NewFlagSet("copilot-insights fake-server", flag.ExitOnError)
+	port := flags.Int("port", 8080, "The port to listen on")
+	fixtures := flags.String("fixtures", "", "Serve the usage.json, metrics.json and billing.json in this directory instead of generated data")
+	generatorConfig := generatorFlags(flags)
+	enterprises := flags.String("enterprises", "", "A comma-separated list of names to serve as enterprises, every other name is an organization")
+	orgs := flags.String("orgs", "", "A comma-separated list of organizations listed as members of every enterprise")
+	debug := flags.Bool("debug", false, "Enable debug mode")
+	flags.Parse(args)
+
+	if *debug {
+		enableDebug()
+	}
+
+	config, err := generatorConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	ctx, cancel := newContext(0)
+	defer cancel()
+
+	var source api.DataSource
+	if *fixtures != "" {
+		source = api.NewFileSource(*fixtures, true)
+	} else {
+		source = fake.NewGeneratedSource(config)
+	}
+
+	addr := fmt.Sprintf("localhost:%d", *port)
+	fmt.Printf("Serving a fake GitHub Copilot API on http://%s, press Ctrl+C to stop.\n", addr)
+	fmt.Printf("Try: gh copilot-insights --hostname %s --scope my-org --output summary\n", addr)
+	if err := fake.NewServer(source, splitList(*enterprises), splitList(*orgs)).ListenAndServe(ctx, addr); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runGenerate(args []string) {
+	flags := flag.NewFlagSet("copilot-insights generate", flag.ExitOnError)
+	outputDir := flags.String("output-dir", "", "The directory to write usage.json, metrics.json and billing.json to")
+	scope := flags.String("scope", "example-org", "The scope name the generated logins are derived from")
+	generatorConfig := generatorFlags(flags)
+	flags.Parse(args)
+
+	if *outputDir == "" {
+		fmt.Println("Error: --output-dir is required")
+		os.Exit(exitUsage)
+	}
+	config, err := generatorConfig()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
+	if err := fake.Generate(config, *scope).WriteDir(*outputDir); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Generated %d days of data for %d users in %s, read it with --from-dir %s\n", config.Days, config.Users, *outputDir, *outputDir)
+}
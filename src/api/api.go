@@ -1,40 +1,16 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
-	"github.com/cli/go-gh"
 	"github.com/cli/go-gh/pkg/api"
 	logger "github.com/sirupsen/logrus"
 )
 
-func determineEndpoint(scope string) (string, error) {
-	client, err := getRESTClient()
-	if err != nil {
-		logger.Debugf("Error creating REST client: %v", err)
-		return "", err
-	}
-
-	// Check if the scope is an organization
-	orgEndpoint := fmt.Sprintf("orgs/%s", scope)
-	var orgResponse map[string]interface{}
-	err = client.Get(orgEndpoint, &orgResponse)
-	if err == nil && orgResponse != nil {
-		return "orgs", nil
-	}
-
-	// Check if the scope is an enterprise
-	enterpriseEndpoint := fmt.Sprintf("enterprises/%s/properties/schema", scope)
-	var enterpriseResponse []map[string]interface{}
-	err = client.Get(enterpriseEndpoint, &enterpriseResponse)
-	if err == nil && enterpriseResponse != nil {
-		return "enterprises", nil
-	}
-
-	logger.Debugf("Invalid scope: %s", scope)
-	return "", fmt.Errorf("invalid scope: %s", scope)
-}
-
 ///
 /// 1. Adoption & Utilization
 // 🚀 Seat Utilization Rate = Active Users This Cycle / Total Paid Seats
@@ -90,18 +66,99 @@ func determineEndpoint(scope string) (string, error) {
 // Insight: Identifies IDE preference trends (VSCode vs. JetBrains, Neovim).
 // Action: Optimize support/training per IDE.
 
+// Sources of the suggestion, acceptance and line counts of an insight
+const (
+	CompletionsFromMetrics = "metrics"
+	CompletionsFromUsage   = "usage"
+)
+
+// completionTotals are the code completion counters of a part of the metrics
+type completionTotals struct {
+	suggestions, acceptances, linesSuggested, linesAccepted int
+}
+
+// addEditor sums the models → languages tree of an editor
+func (t *completionTotals) addEditor(editor EditorMetrics) {
+	for _, model := range editor.Models {
+		for _, language := range model.Languages {
+			t.suggestions += language.TotalCodeSuggestions
+			t.acceptances += language.TotalCodeAcceptances
+			t.linesSuggested += language.TotalCodeLinesSuggested
+			t.linesAccepted += language.TotalCodeLinesAccepted
+		}
+	}
+}
+
+// completionCounts sums the code completion counters of the editors → models → languages tree of the metrics
+func completionCounts(metrics []CopilotMetrics) (suggestions, acceptances, linesSuggested, linesAccepted int) {
+	var totals completionTotals
+	for _, m := range metrics {
+		for _, editor := range m.CopilotIDECodeCompletions.Editors {
+			totals.addEditor(editor)
+		}
+	}
+	return totals.suggestions, totals.acceptances, totals.linesSuggested, totals.linesAccepted
+}
+
+// usageCounts sums the same counters from the legacy usage endpoint
+func usageCounts(usage []CopilotUsage) (suggestions, acceptances, linesSuggested, linesAccepted int) {
+	for _, u := range usage {
+		suggestions += u.TotalSuggestionsCount
+		acceptances += u.TotalAcceptancesCount
+		linesSuggested += u.TotalLinesSuggested
+		linesAccepted += u.TotalLinesAccepted
+	}
+	return suggestions, acceptances, linesSuggested, linesAccepted
+}
+
+// usageRetired reports whether the legacy usage endpoint answered that it is gone, which is no failure as the
+// metrics cover everything it reported
+func usageRetired(err error) bool {
+	status := httpStatus(err)
+	return status == http.StatusNotFound || status == http.StatusGone
+}
+
+// ratio divides without producing NaN or infinity, days and scopes without activity count as zero
+func ratio(numerator, denominator float64) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+// editorUsers counts the engaged users of every editor on a day, from both code completions and chat. Merged
+// data lists an editor once per scope, so each feature is summed per editor first. A user of both features in
+// the same editor is reported by each, so the larger of the two sums is taken.
+func editorUsers(m CopilotMetrics) map[string]int {
+	users := make(map[string]int)
+	for _, feature := range [][]EditorMetrics{m.CopilotIDECodeCompletions.Editors, m.CopilotIDEChat.Editors} {
+		featureUsers := make(map[string]int)
+		for _, editor := range feature {
+			featureUsers[editor.Name] += editor.TotalEngagedUsers
+		}
+		for name, count := range featureUsers {
+			if current, ok := users[name]; !ok || count > current {
+				users[name] = count
+			}
+		}
+	}
+	return users
+}
+
 func getInsights(scopeName, scopeType string, usage []CopilotUsage, metrics []CopilotMetrics, billing CopilotBilling) Insight {
-	var totalActiveUsers, totalActiveUsersMetrics, totalEngagedUsers, totalSuggestions, totalAcceptances, totalLinesSuggested, totalLinesAccepted int
+	var totalActiveUsersMetrics, totalEngagedUsers int
 	var totalIDEUsers, totalDotcomUsers int
 	featureEngagementRate := make(map[string]float64)
 	editorPreferenceIndex := make(map[string]float64)
-
-	for _, u := range usage {
-		totalActiveUsers += u.TotalActiveUsers
-		totalSuggestions += u.TotalSuggestionsCount
-		totalAcceptances += u.TotalAcceptancesCount
-		totalLinesSuggested += u.TotalLinesSuggested
-		totalLinesAccepted += u.TotalLinesAccepted
+	editorCompletions := make(map[string]*completionTotals)
+
+	// The metrics API breaks completions down by editor, model and language, the legacy usage endpoint is only
+	// used for data without that breakdown
+	completionsSource := CompletionsFromMetrics
+	totalSuggestions, totalAcceptances, totalLinesSuggested, totalLinesAccepted := completionCounts(metrics)
+	if totalSuggestions == 0 && len(usage) > 0 {
+		completionsSource = CompletionsFromUsage
+		totalSuggestions, totalAcceptances, totalLinesSuggested, totalLinesAccepted = usageCounts(usage)
 	}
 
 	for _, m := range metrics {
@@ -109,8 +166,16 @@ func getInsights(scopeName, scopeType string, usage []CopilotUsage, metrics []Co
 		totalActiveUsersMetrics += m.TotalActiveUsers
 		totalIDEUsers += m.CopilotIDEChat.TotalEngagedUsers + m.CopilotIDECodeCompletions.TotalEngagedUsers
 		totalDotcomUsers += m.CopilotDotcomChat.TotalEngagedUsers + m.CopilotDotcomPullRequests.TotalEngagedUsers
+		for name, users := range editorUsers(m) {
+			editorPreferenceIndex[name] += float64(users)
+		}
 		for _, editor := range m.CopilotIDECodeCompletions.Editors {
-			editorPreferenceIndex[editor.Name] += float64(editor.TotalEngagedUsers)
+			totals, ok := editorCompletions[editor.Name]
+			if !ok {
+				totals = &completionTotals{}
+				editorCompletions[editor.Name] = totals
+			}
+			totals.addEditor(editor)
 		}
 		for _, feature := range []struct {
 			name  string
@@ -124,27 +189,51 @@ func getInsights(scopeName, scopeType string, usage []CopilotUsage, metrics []Co
 		}
 	}
 
-	avgEngagedUsers := float64(totalEngagedUsers) / float64(len(metrics))
+	avgEngagedUsers := ratio(float64(totalEngagedUsers), float64(len(metrics)))
 
-	seatUtilizationRate := float64(avgEngagedUsers) / float64(billing.Total)
-	activeVsEngagedUsers := float64(totalEngagedUsers) / float64(totalActiveUsersMetrics)
-	codeAcceptanceRate := float64(totalAcceptances) / float64(totalSuggestions)
-	codeAdoptionEfficiency := float64(totalLinesAccepted) / float64(totalLinesSuggested)
-	costPerEngagedUser := float64(billing.Total) / float64(totalEngagedUsers)
-	ideAdoption := float64(totalIDEUsers) / float64(totalEngagedUsers)
-	dotcomAdoption := float64(totalDotcomUsers) / float64(totalEngagedUsers)
+	seatUtilizationRate := ratio(avgEngagedUsers, float64(billing.Total))
+	activeVsEngagedUsers := ratio(float64(totalEngagedUsers), float64(totalActiveUsersMetrics))
+	codeAcceptanceRate := ratio(float64(totalAcceptances), float64(totalSuggestions))
+	codeAdoptionEfficiency := ratio(float64(totalLinesAccepted), float64(totalLinesSuggested))
+	costPerEngagedUser := ratio(float64(billing.Total), float64(totalEngagedUsers))
+	ideAdoption := ratio(float64(totalIDEUsers), float64(totalEngagedUsers))
+	dotcomAdoption := ratio(float64(totalDotcomUsers), float64(totalEngagedUsers))
 
 	for key := range featureEngagementRate {
-		featureEngagementRate[key] /= float64(totalEngagedUsers)
+		featureEngagementRate[key] = ratio(featureEngagementRate[key], float64(totalEngagedUsers))
 	}
 
-	for key := range editorPreferenceIndex {
-		editorPreferenceIndex[key] /= float64(totalEngagedUsers)
+	editorPreferenceMetrics := make(map[string]Metric)
+	for editor, users := range editorPreferenceIndex {
+		editorPreferenceMetrics[editor] = Metric{
+			Value:       ratio(users, float64(totalEngagedUsers)),
+			DisplayName: "Editor Preference Index",
+			Description: "Identifies IDE preference trends (VSCode vs. JetBrains, Neovim). Calculated as Users per Editor (code completions or chat) / Total Users.",
+			Category:    "Strategic Growth",
+		}
+	}
+
+	editorAcceptanceRate := make(map[string]Metric)
+	editorLineAdoptionEfficiency := make(map[string]Metric)
+	for editor, totals := range editorCompletions {
+		editorAcceptanceRate[editor] = Metric{
+			Value:       ratio(float64(totals.acceptances), float64(totals.suggestions)),
+			DisplayName: "Editor Acceptance Rate",
+			Description: "Compares how much developers trust the suggestions in each editor. Calculated as Acceptances in Editor / Suggestions in Editor.",
+			Category:    "Productivity Impact",
+		}
+		editorLineAdoptionEfficiency[editor] = Metric{
+			Value:       ratio(float64(totals.linesAccepted), float64(totals.linesSuggested)),
+			DisplayName: "Editor Line Adoption Efficiency",
+			Description: "Compares how much of the suggested code is kept in each editor. Calculated as Lines Accepted in Editor / Lines Suggested in Editor.",
+			Category:    "Productivity Impact",
+		}
 	}
 
 	return Insight{
-		ScopeName: scopeName,
-		ScopeType: scopeType,
+		ScopeName:         scopeName,
+		ScopeType:         scopeType,
+		CompletionsSource: completionsSource,
 		AdoptionUtilization: AdoptionUtilizationMetrics{
 			SeatUtilizationRate: Metric{
 				Value:       seatUtilizationRate,
@@ -210,6 +299,8 @@ func getInsights(scopeName, scopeType string, usage []CopilotUsage, metrics []Co
 				Description: "Determines if chat is enhancing workflows. Calculated as Chat Users / Total Engaged Users.",
 				Category:    "Productivity Impact",
 			},
+			EditorAcceptanceRate:         editorAcceptanceRate,
+			EditorLineAdoptionEfficiency: editorLineAdoptionEfficiency,
 		},
 		ROICostEfficiency: ROICostEfficiencyMetrics{
 			CostPerEngagedUser: Metric{
@@ -246,69 +337,198 @@ func getInsights(scopeName, scopeType string, usage []CopilotUsage, metrics []Co
 				Description: "Gauges organic adoption growth. Calculated as New Users Added / Total Users.",
 				Category:    "Strategic Growth",
 			},
-			EditorPreferenceIndex: map[string]Metric{
-				"vscode": {
-					Value:       editorPreferenceIndex["vscode"],
-					DisplayName: "Editor Preference Index",
-					Description: "Identifies IDE preference trends (VSCode vs. JetBrains, Neovim). Calculated as Users per Editor / Total Users.",
-					Category:    "Strategic Growth",
-				},
-				"jetbrains": {
-					Value:       editorPreferenceIndex["jetbrains"],
-					DisplayName: "Editor Preference Index",
-					Description: "Identifies IDE preference trends (VSCode vs. JetBrains, Neovim). Calculated as Users per Editor / Total Users.",
-					Category:    "Strategic Growth",
-				},
-			},
+			EditorPreferenceIndex: editorPreferenceMetrics,
 		},
 	}
 }
 
-func getRESTClient() (api.RESTClient, error) {
-	client, err := gh.RESTClient(nil)
+// fetchBilling loads every seat page of the billing endpoint
+func fetchBilling(ctx context.Context, client api.RESTClient, endpoint string) (CopilotBilling, error) {
+	var billing CopilotBilling
+	err := getPaginated(ctx, client, fmt.Sprintf("%s/billing/seats", endpoint), func(d *json.Decoder) error {
+		var page CopilotBilling
+		if err := d.Decode(&page); err != nil {
+			return err
+		}
+		billing.Total = page.Total
+		billing.Seats = append(billing.Seats, page.Seats...)
+		return nil
+	})
 	if err != nil {
-		logger.Debugf("Error creating REST client: %v", err)
-		return nil, err
+		return CopilotBilling{}, err
 	}
-	return client, nil
+	logger.Debugf("Loaded %d of %d seats from %s", len(billing.Seats), billing.Total, endpoint)
+	return billing, nil
 }
 
-func FetchCopilotUsage(scopeName string) ([]Insight, error) {
-	client, err := getRESTClient()
+// fetchTeamSeats counts the seats of a team, which has no billing endpoint of its own, as the seats of the
+// scope that are assigned to a team member or through the team. When the seats of the scope can't be read,
+// every team member counts as a seat.
+func fetchTeamSeats(ctx context.Context, client api.RESTClient, scopeType, scopeName, teamName string) (CopilotBilling, error) {
+	members, err := fetchTeamLogins(ctx, client, scopeType, scopeName, teamName)
 	if err != nil {
-		logger.Debugf("Error creating REST client: %v", err)
-		return nil, err
+		return CopilotBilling{}, err
 	}
 
-	scopeType, err := determineEndpoint(scopeName)
+	billing, err := fetchBilling(ctx, client, fmt.Sprintf("%s/%s/copilot", scopeType, scopeName))
 	if err != nil {
-		logger.Debugf("Error determining endpoint for scope %s: %v", scopeName, err)
-		return nil, err
+		if ctx.Err() != nil {
+			return CopilotBilling{}, ctx.Err()
+		}
+		logger.Warnf("Counting every member of team %s as a seat, the seats of %s can't be read: %v", teamName, scopeName, err)
+		return CopilotBilling{Total: len(members)}, nil
 	}
 
-	endpoint := fmt.Sprintf("%s/%s/copilot", scopeType, scopeName)
+	isMember := make(map[string]bool, len(members))
+	for _, login := range members {
+		isMember[strings.ToLower(login)] = true
+	}
+	team := CopilotBilling{Seats: []Seat{}}
+	for _, seat := range billing.Seats {
+		assignedThroughTeam := seat.AssigningTeam != nil && strings.EqualFold(seat.AssigningTeam.Slug, teamName)
+		if assignedThroughTeam || isMember[strings.ToLower(seat.Assignee.Login)] {
+			team.Seats = append(team.Seats, seat)
+		}
+	}
+	team.Total = len(team.Seats)
+	logger.Debugf("Team %s has %d members and %d seats", teamName, len(members), team.Total)
+	return team, nil
+}
 
-	var usage []CopilotUsage
-	err = client.Get(fmt.Sprintf("%s/usage", endpoint), &usage)
-	if err != nil {
-		logger.Debugf("Error fetching usage data from endpoint %s: %v", endpoint, err)
-		return nil, err
+// fetchTeamLogins lists the members of a team, enterprise teams list them as memberships
+func fetchTeamLogins(ctx context.Context, client api.RESTClient, scopeType, scopeName, teamName string) ([]string, error) {
+	path := fmt.Sprintf("orgs/%s/teams/%s/members", scopeName, teamName)
+	if scopeType == "enterprises" {
+		path = fmt.Sprintf("enterprises/%s/teams/%s/memberships", scopeName, teamName)
 	}
+	var logins []string
+	err := getPaginated(ctx, client, path, func(d *json.Decoder) error {
+		var page []SeatAssignee
+		if err := d.Decode(&page); err != nil {
+			return err
+		}
+		for _, member := range page {
+			logins = append(logins, member.Login)
+		}
+		return nil
+	})
+	return logins, err
+}
 
-	var metrics []CopilotMetrics
-	err = client.Get(fmt.Sprintf("%s/metrics", endpoint), &metrics)
-	if err != nil {
-		logger.Debugf("Error fetching metrics data from endpoint %s: %v", endpoint, err)
-		return nil, err
+func teamScopeType(scopeType string) string {
+	if scopeType == "enterprises" {
+		return "enterprise-team"
+	}
+	return "team"
+}
+
+// scopeData is the raw data fetched for a single scope
+type scopeData struct {
+	Name    string
+	Type    string
+	Usage   []CopilotUsage
+	Metrics []CopilotMetrics
+	Billing CopilotBilling
+}
+
+// InsightOptions selects the optional sections of the insights
+type InsightOptions struct {
+	// Series computes every insight per day and per ISO week as well as over the whole window
+	Series bool
+	// Languages breaks the code completions down by language, nil leaves the breakdown out
+	Languages *LanguageBreakdown
+}
+
+func (d scopeData) insight(window DateRange, options InsightOptions) Insight {
+	insight := getInsights(d.Name, d.Type, d.Usage, d.Metrics, d.Billing)
+	insight.Window = effectiveWindow(window, d.Metrics)
+	insight.RawFacts = flattenFacts(d.Usage, d.Metrics)
+	if options.Languages != nil {
+		insight.Languages = options.Languages.apply(getLanguageInsights(d.Metrics))
 	}
+	if options.Series {
+		insight.Series = d.insightSeries()
+	}
+	return insight
+}
 
-	var billing CopilotBilling
-	err = client.Get(fmt.Sprintf("%s/billing/seats", endpoint), &billing)
+func fetchScopeData(ctx context.Context, source DataSource, scope Scope, window DateRange) (scopeData, error) {
+	data := scopeData{Name: scope.Name, Type: scope.Type}
+	if scope.Team != "" {
+		data.Name = fmt.Sprintf("%s/%s", scope.Name, scope.Team)
+		data.Type = teamScopeType(scope.Type)
+	}
+
+	err := runConcurrently(ctx,
+		func(ctx context.Context) (err error) {
+			data.Usage, err = source.Usage(ctx, scope, window)
+			if usageRetired(err) {
+				logger.Debugf("Usage endpoint unavailable for %s (HTTP %d), using the metrics only", data.Name, httpStatus(err))
+				return nil
+			}
+			if err != nil {
+				logger.Debugf("Error fetching usage data for %s: %v", data.Name, err)
+			}
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			data.Metrics, err = source.Metrics(ctx, scope, window)
+			if err != nil {
+				logger.Debugf("Error fetching metrics data for %s: %v", data.Name, err)
+			}
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			data.Billing, err = source.Billing(ctx, scope)
+			if err != nil {
+				logger.Debugf("Error fetching billing data for %s: %v", data.Name, err)
+			}
+			return err
+		},
+	)
 	if err != nil {
-		logger.Debugf("Error fetching billing data from endpoint %s: %v", endpoint, err)
+		return scopeData{}, notFound(data.Name, err)
+	}
+
+	// Sources may return days outside the requested window, so filter client-side as well
+	data.Usage = filterUsage(data.Usage, window)
+	data.Metrics = filterMetrics(data.Metrics, window)
+	if len(data.Metrics) == 0 {
+		return scopeData{}, &InsufficientDataError{Scope: data.Name}
+	}
+	return data, nil
+}
+
+// FetchCopilotUsage returns one insight per scope, and when there are several scopes an additional insight
+// computed from their combined data
+func FetchCopilotUsage(ctx context.Context, source DataSource, scopeNames []string, teamName string, window DateRange, options InsightOptions) ([]Insight, error) {
+	data := make([]scopeData, len(scopeNames))
+	tasks := make([]func(context.Context) error, 0, len(scopeNames))
+	for i, scopeName := range scopeNames {
+		i, scopeName := i, scopeName
+		tasks = append(tasks, func(ctx context.Context) error {
+			scopeType, err := source.ScopeType(ctx, scopeName)
+			if err != nil {
+				logger.Debugf("Error determining endpoint for scope %s: %v", scopeName, err)
+				return fmt.Errorf("%s: %w", scopeName, err)
+			}
+			data[i], err = fetchScopeData(ctx, source, Scope{Type: scopeType, Name: scopeName, Team: teamName}, window)
+			if err != nil {
+				return fmt.Errorf("%s: %w", scopeName, err)
+			}
+			return nil
+		})
+	}
+	if err := runLimited(ctx, scopeConcurrency, tasks...); err != nil {
 		return nil, err
 	}
 
-	insight := getInsights(scopeName, scopeType, usage, metrics, billing)
-	return []Insight{insight}, nil
+	insights := make([]Insight, 0, len(data)+1)
+	for _, d := range data {
+		insights = append(insights, d.insight(window, options))
+	}
+	if len(data) > 1 {
+		insights = append(insights, mergeScopeData(data).insight(window, options))
+	}
+	return insights, nil
 }
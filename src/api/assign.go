@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/api"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	AssignmentPending         = "pending"
+	AssignmentAssigned        = "assigned"
+	AssignmentAlreadyAssigned = "already_assigned"
+	AssignmentNotAMember      = "not_a_member"
+	AssignmentTeamNotFound    = "team_not_found"
+	AssignmentFailed          = "failed"
+)
+
+// SeatAssignment is a single user or team that should receive Copilot seats
+type SeatAssignment struct {
+	Login string `json:"login,omitempty"`
+	Team  string `json:"team,omitempty"`
+}
+
+func (a SeatAssignment) String() string {
+	if a.Team != "" {
+		return "team:" + a.Team
+	}
+	return a.Login
+}
+
+type AssignmentResult struct {
+	SeatAssignment
+	Status   string `json:"status"`
+	NewSeats int    `json:"new_seats"`
+	Error    string `json:"error,omitempty"`
+}
+
+type AssignmentPlan struct {
+	Organization   string             `json:"organization"`
+	CurrentSeats   int                `json:"current_seats"`
+	ProjectedSeats int                `json:"projected_seats"`
+	Results        []AssignmentResult `json:"results"`
+}
+
+func (p AssignmentPlan) Pending() int {
+	pending := 0
+	for _, result := range p.Results {
+		if result.Status == AssignmentPending {
+			pending++
+		}
+	}
+	return pending
+}
+
+func isNotFound(err error) bool {
+	var httpErr api.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+func isOrganizationMember(ctx context.Context, client api.RESTClient, organization, login string) (bool, error) {
+	resp, err := client.RequestWithContext(ctx, http.MethodGet, fmt.Sprintf("orgs/%s/members/%s", organization, login), nil)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusNoContent, nil
+}
+
+// PlanSeatAssignment checks every requested user and team against the current seats and the organization
+// membership, and projects how many seats applying the plan would add
+func PlanSeatAssignment(ctx context.Context, organization string, assignments []SeatAssignment, billing CopilotBilling) (AssignmentPlan, error) {
+	client, err := getOrganizationClient(ctx, organization)
+	if err != nil {
+		return AssignmentPlan{}, err
+	}
+
+	seated := make(map[string]bool)
+	for _, seat := range billing.Seats {
+		seated[strings.ToLower(seat.Assignee.Login)] = true
+	}
+
+	plan := AssignmentPlan{Organization: organization, CurrentSeats: billing.Total, ProjectedSeats: billing.Total}
+	for _, assignment := range assignments {
+		result := AssignmentResult{SeatAssignment: assignment}
+
+		if assignment.Team != "" {
+			members, err := fetchTeamLogins(ctx, client, "orgs", organization, assignment.Team)
+			switch {
+			case isNotFound(err):
+				result.Status = AssignmentTeamNotFound
+			case err != nil:
+				return AssignmentPlan{}, err
+			default:
+				for _, login := range members {
+					if !seated[strings.ToLower(login)] {
+						seated[strings.ToLower(login)] = true
+						result.NewSeats++
+					}
+				}
+				result.Status = AssignmentPending
+				if result.NewSeats == 0 {
+					result.Status = AssignmentAlreadyAssigned
+				}
+			}
+		} else if seated[strings.ToLower(assignment.Login)] {
+			result.Status = AssignmentAlreadyAssigned
+		} else {
+			member, err := isOrganizationMember(ctx, client, organization, assignment.Login)
+			if err != nil {
+				return AssignmentPlan{}, err
+			}
+			result.Status = AssignmentNotAMember
+			if member {
+				seated[strings.ToLower(assignment.Login)] = true
+				result.Status = AssignmentPending
+				result.NewSeats = 1
+			}
+		}
+
+		logger.Debugf("Assignment of %s: %s (%d new seats)", assignment, result.Status, result.NewSeats)
+		plan.ProjectedSeats += result.NewSeats
+		plan.Results = append(plan.Results, result)
+	}
+	return plan, nil
+}
+
+// ApplySeatAssignment assigns the pending users and teams one by one, so each result reflects its own outcome
+func ApplySeatAssignment(ctx context.Context, plan AssignmentPlan) (AssignmentPlan, error) {
+	client, err := getOrganizationClient(ctx, plan.Organization)
+	if err != nil {
+		return plan, err
+	}
+
+	for i, result := range plan.Results {
+		if result.Status != AssignmentPending {
+			continue
+		}
+
+		path := fmt.Sprintf("orgs/%s/copilot/billing/selected_users", plan.Organization)
+		body := map[string][]string{"selected_usernames": {result.Login}}
+		if result.Team != "" {
+			path = fmt.Sprintf("orgs/%s/copilot/billing/selected_teams", plan.Organization)
+			body = map[string][]string{"selected_teams": {result.Team}}
+		}
+
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return plan, err
+		}
+
+		var response struct {
+			SeatsCreated int `json:"seats_created"`
+		}
+		err = client.DoWithContext(ctx, http.MethodPost, path, bytes.NewReader(payload), &response)
+		if err != nil {
+			logger.Debugf("Error assigning seat to %s: %v", result.SeatAssignment, err)
+			plan.Results[i].Status = AssignmentFailed
+			plan.Results[i].Error = err.Error()
+			continue
+		}
+
+		plan.Results[i].Status = AssignmentAssigned
+		plan.Results[i].NewSeats = response.SeatsCreated
+		if response.SeatsCreated == 0 {
+			plan.Results[i].Status = AssignmentAlreadyAssigned
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/api"
+	logger "github.com/sirupsen/logrus"
+)
+
+const enterpriseOrganizationsQuery = `query($slug: String!, $cursor: String) {
+  enterprise(slug: $slug) {
+    organizations(first: 100, after: $cursor) {
+      nodes { login }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// fetchEnterpriseOrganizations lists the organizations of an enterprise, which only the GraphQL API exposes
+func fetchEnterpriseOrganizations(ctx context.Context, client api.RESTClient, enterprise string) ([]string, error) {
+	var logins []string
+	var cursor *string
+	for {
+		body, err := json.Marshal(map[string]interface{}{
+			"query":     enterpriseOrganizationsQuery,
+			"variables": map[string]interface{}{"slug": enterprise, "cursor": cursor},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			Data struct {
+				Enterprise *struct {
+					Organizations struct {
+						Nodes []struct {
+							Login string `json:"login"`
+						} `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"organizations"`
+				} `json:"enterprise"`
+			} `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		err = client.DoWithContext(ctx, http.MethodPost, graphQLURL(currentHost()), bytes.NewReader(body), &response)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Errors) > 0 {
+			messages := make([]string, 0, len(response.Errors))
+			for _, e := range response.Errors {
+				messages = append(messages, e.Message)
+			}
+			return nil, fmt.Errorf("listing organizations of %s: %s", enterprise, strings.Join(messages, ", "))
+		}
+		if response.Data.Enterprise == nil {
+			return nil, &ScopeNotFoundError{Scope: enterprise}
+		}
+
+		organizations := response.Data.Enterprise.Organizations
+		for _, node := range organizations.Nodes {
+			logins = append(logins, node.Login)
+		}
+		if !organizations.PageInfo.HasNextPage {
+			return logins, nil
+		}
+		cursor = &organizations.PageInfo.EndCursor
+	}
+}
+
+// FetchEnterpriseBreakdown returns the insight of the enterprise followed by one insight per organization,
+// ranked by seat utilization with the least utilized first. Organizations whose data can't be fetched,
+// for example because the metrics policy is disabled, are skipped with a warning.
+func FetchEnterpriseBreakdown(ctx context.Context, source DataSource, enterprise string, window DateRange, options InsightOptions) ([]Insight, error) {
+	lister, ok := source.(organizationLister)
+	if !ok {
+		return nil, fmt.Errorf("a breakdown by organization is not supported by this data source")
+	}
+
+	scopeType, err := source.ScopeType(ctx, enterprise)
+	if err != nil {
+		logger.Debugf("Error determining endpoint for scope %s: %v", enterprise, err)
+		return nil, err
+	}
+	if scopeType != "enterprises" {
+		return nil, fmt.Errorf("a breakdown by organization requires an enterprise scope, %s is an organization", enterprise)
+	}
+
+	organizations, err := lister.Organizations(ctx, enterprise)
+	if err != nil {
+		logger.Debugf("Error listing organizations of %s: %v", enterprise, err)
+		return nil, err
+	}
+	logger.Debugf("Enterprise %s has %d organizations", enterprise, len(organizations))
+
+	var total scopeData
+	data := make([]*scopeData, len(organizations))
+	tasks := []func(context.Context) error{
+		func(ctx context.Context) (err error) {
+			total, err = fetchScopeData(ctx, source, Scope{Type: scopeType, Name: enterprise}, window)
+			return err
+		},
+	}
+	for i, organization := range organizations {
+		i, organization := i, organization
+		tasks = append(tasks, func(ctx context.Context) error {
+			d, err := fetchScopeData(ctx, source, Scope{Type: "orgs", Name: organization}, window)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				logger.Warnf("Skipping organization %s: %v", organization, err)
+				return nil
+			}
+			data[i] = &d
+			return nil
+		})
+	}
+	if err := runLimited(ctx, scopeConcurrency, tasks...); err != nil {
+		return nil, err
+	}
+
+	type ranked struct {
+		insight Insight
+		seats   int
+	}
+	var ranking []ranked
+	for _, d := range data {
+		if d != nil {
+			ranking = append(ranking, ranked{insight: d.insight(window, options), seats: d.Billing.Total})
+		}
+	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		// Organizations without seats have no utilization and go last
+		if (ranking[i].seats == 0) != (ranking[j].seats == 0) {
+			return ranking[i].seats != 0
+		}
+		return ranking[i].insight.AdoptionUtilization.SeatUtilizationRate.Value < ranking[j].insight.AdoptionUtilization.SeatUtilizationRate.Value
+	})
+
+	insights := make([]Insight, 0, len(ranking))
+	for _, r := range ranking {
+		insights = append(insights, r.insight)
+	}
+	return append([]Insight{total.insight(window, options)}, insights...), nil
+}
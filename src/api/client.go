@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/cli/go-gh"
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/cli/go-gh/pkg/auth"
+	logger "github.com/sirupsen/logrus"
+)
+
+// hostname overrides the host gh is configured for, see SetHostname
+var hostname string
+
+// SetHostname routes every request to a GitHub Enterprise Server or GHE.com host instead of the gh default
+func SetHostname(host string) {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), "/")
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	hostname = strings.TrimPrefix(host, "api.")
+}
+
+// currentHost is the --hostname flag, otherwise the gh default which honors GH_HOST
+func currentHost() string {
+	if hostname != "" {
+		return hostname
+	}
+	host, _ := auth.DefaultHost()
+	return host
+}
+
+// Hostname returns the host requests are sent to
+func Hostname() string {
+	return currentHost()
+}
+
+// isLocalHost detects hosts like the fake server, which are served over plain HTTP
+func isLocalHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// apiBaseURL returns the REST API root of a host. GHE.com tenants serve the API from an api. subdomain
+// like github.com does, while GitHub Enterprise Server serves it under /api/v3.
+func apiBaseURL(host string) string {
+	switch {
+	case isLocalHost(host):
+		return fmt.Sprintf("http://%s/api/v3/", host)
+	case host == "github.com":
+		return "https://api.github.com/"
+	case strings.HasSuffix(host, ".ghe.com"):
+		return fmt.Sprintf("https://api.%s/", host)
+	default:
+		return fmt.Sprintf("https://%s/api/v3/", host)
+	}
+}
+
+// graphQLURL returns the GraphQL endpoint of a host, which GitHub Enterprise Server serves outside /api/v3
+func graphQLURL(host string) string {
+	if host == "github.com" || strings.HasSuffix(host, ".ghe.com") {
+		return apiBaseURL(host) + "graphql"
+	}
+	if isLocalHost(host) {
+		return fmt.Sprintf("http://%s/api/graphql", host)
+	}
+	return fmt.Sprintf("https://%s/api/graphql", host)
+}
+
+// restClient resolves relative paths against the API root of its host, go-gh assumes every host other than
+// github.com is a GitHub Enterprise Server. Errors are returned as the typed errors of classifyError.
+type restClient struct {
+	api.RESTClient
+	baseURL string
+}
+
+func (c restClient) url(path string) string {
+	if strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://") {
+		return path
+	}
+	return c.baseURL + strings.TrimPrefix(path, "/")
+}
+
+func (c restClient) DoWithContext(ctx context.Context, method string, path string, body io.Reader, response interface{}) error {
+	return classifyError(path, c.RESTClient.DoWithContext(ctx, method, c.url(path), body, response))
+}
+
+func (c restClient) RequestWithContext(ctx context.Context, method string, path string, body io.Reader) (*http.Response, error) {
+	resp, err := c.RESTClient.RequestWithContext(ctx, method, c.url(path), body)
+	return resp, classifyError(path, err)
+}
+
+func getRESTClient() (api.RESTClient, error) {
+	host := currentHost()
+	opts := &api.ClientOptions{
+		Host:      host,
+		Transport: newRetryTransport(http.DefaultTransport),
+	}
+	switch {
+	case replayDir != "":
+		transport, err := newReplayTransport(replayDir)
+		if err != nil {
+			return nil, err
+		}
+		// Replays never reach GitHub, so they don't need the token gh is logged in with
+		opts.Transport = transport
+		opts.AuthToken = "replay"
+	case recordDir != "":
+		opts.Transport = newRecordingTransport(recordDir, opts.Transport)
+	}
+	if token, _ := auth.TokenForHost(host); token == "" && isLocalHost(host) && opts.AuthToken == "" {
+		// Local servers such as the fake server don't check the token
+		opts.AuthToken = "local"
+	}
+
+	client, err := gh.RESTClient(opts)
+	if err != nil {
+		logger.Debugf("Error creating REST client: %v", err)
+		return nil, err
+	}
+	logger.Debugf("Using API %s", apiBaseURL(host))
+	return restClient{RESTClient: client, baseURL: apiBaseURL(host)}, nil
+}
+
+func get(ctx context.Context, client api.RESTClient, path string, response interface{}) error {
+	return client.DoWithContext(ctx, http.MethodGet, path, nil, response)
+}
@@ -0,0 +1,29 @@
+package api
+
+// Compare records the value every metric of current had in previous, matching insights by scope. Scopes
+// missing from previous are left without previous values.
+func Compare(current, previous []Insight) {
+	byScope := make(map[string]Insight)
+	for _, insight := range previous {
+		byScope[insight.ScopeName] = insight
+	}
+
+	for i := range current {
+		before, ok := byScope[current[i].ScopeName]
+		if !ok {
+			continue
+		}
+		values := make(map[string]float64)
+		for _, metric := range before.Metrics() {
+			values[metric.Key] = metric.Metric.Value
+		}
+
+		window := before.Window
+		current[i].PreviousWindow = &window
+		current[i].visitMetrics(func(key string, metric *Metric) {
+			if value, ok := values[key]; ok {
+				metric.Previous = &value
+			}
+		})
+	}
+}
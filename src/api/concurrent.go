@@ -0,0 +1,42 @@
+package api
+
+import "context"
+
+// scopeConcurrency bounds how many scopes, such as the organizations of a breakdown, are fetched at once
+const scopeConcurrency = 4
+
+// runConcurrently runs every task in its own goroutine and returns the first error. The context passed to
+// the tasks is cancelled as soon as one of them fails, so the others stop early.
+func runConcurrently(ctx context.Context, tasks ...func(context.Context) error) error {
+	return runLimited(ctx, len(tasks), tasks...)
+}
+
+// runLimited is runConcurrently with at most limit tasks running at once, which keeps large fan-outs clear
+// of the secondary rate limits
+func runLimited(ctx context.Context, limit int, tasks ...func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	slots := make(chan struct{}, limit)
+	errs := make(chan error, len(tasks))
+	for _, task := range tasks {
+		go func(task func(context.Context) error) {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				errs <- task(ctx)
+			case <-ctx.Done():
+				errs <- ctx.Err()
+			}
+		}(task)
+	}
+
+	var first error
+	for range tasks {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+			cancel()
+		}
+	}
+	return first
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/pkg/api"
+	"github.com/cli/go-gh/pkg/auth"
+)
+
+const (
+	CheckPassed  = "pass"
+	CheckWarning = "warn"
+	CheckFailed  = "fail"
+	CheckSkipped = "skip"
+)
+
+// Check is one item of the doctor checklist
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// impliedTokenScopes lists the scopes that also grant a required scope
+var impliedTokenScopes = map[string][]string{
+	"read:org":        {"write:org", "admin:org"},
+	"read:enterprise": {"manage_billing:enterprise", "admin:enterprise"},
+}
+
+func hasTokenScope(granted []string, scope string) bool {
+	for _, g := range granted {
+		if g == scope {
+			return true
+		}
+		for _, implied := range impliedTokenScopes[scope] {
+			if g == implied {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkReachability(ctx context.Context, host string) Check {
+	check := Check{Name: fmt.Sprintf("Host %s is reachable", host)}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBaseURL(host), nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+			check.Status, check.Detail = CheckPassed, fmt.Sprintf("%s answered %s", apiBaseURL(host), resp.Status)
+			return check
+		}
+	}
+	check.Status, check.Detail = CheckFailed, err.Error()
+	return check
+}
+
+// checkTokenScopes reads the scopes GitHub reports for the token, which only classic tokens have
+func checkTokenScopes(ctx context.Context, client api.RESTClient, scopeType string) []Check {
+	var headers http.Header
+	resp, err := client.RequestWithContext(ctx, http.MethodGet, "", nil)
+	var httpErr api.HTTPError
+	switch {
+	case err == nil:
+		resp.Body.Close()
+		headers = resp.Header
+	case errors.As(err, &httpErr):
+		headers = httpErr.Headers
+	default:
+		return []Check{{Name: "Token scopes", Status: CheckFailed, Detail: err.Error()}}
+	}
+
+	if _, ok := headers[http.CanonicalHeaderKey("X-OAuth-Scopes")]; !ok {
+		return []Check{{Name: "Token scopes", Status: CheckWarning,
+			Detail: "the token does not report its scopes, as fine-grained and app tokens don't, see the endpoint checks for its access"}}
+	}
+
+	granted := splitScopes(headers.Get("X-OAuth-Scopes"))
+	accepted := copilotTokenScopes(scopeType)
+	var sufficient string
+	for _, scope := range accepted {
+		if hasTokenScope(granted, scope) {
+			sufficient = scope
+			break
+		}
+	}
+
+	// Any one of the accepted scopes opens the Copilot endpoints, so the others are only worth a warning
+	var checks []Check
+	for _, scope := range accepted {
+		check := Check{Name: fmt.Sprintf("Token has the %s scope", scope), Status: CheckPassed}
+		switch {
+		case hasTokenScope(granted, scope):
+		case sufficient != "":
+			check.Status = CheckWarning
+			check.Detail = fmt.Sprintf("not needed, %s already grants access to the Copilot endpoints", sufficient)
+		default:
+			check.Status = CheckFailed
+			check.Detail = fmt.Sprintf("the token needs one of %s, e.g. add it with: gh auth refresh -h %s -s %s",
+				strings.Join(accepted, ", "), currentHost(), accepted[0])
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// copilotTokenScopes lists the classic token scopes the Copilot endpoints of the scope type accept, any
+// one of them is enough
+func copilotTokenScopes(scopeType string) []string {
+	switch scopeType {
+	case "orgs":
+		return []string{"manage_billing:copilot", "read:org"}
+	case "enterprises":
+		return []string{"manage_billing:copilot", "read:enterprise"}
+	}
+	return []string{"manage_billing:copilot", "read:org", "read:enterprise"}
+}
+
+func checkEndpoint(ctx context.Context, client api.RESTClient, name, path string) Check {
+	check := Check{Name: name}
+	resp, err := client.RequestWithContext(ctx, http.MethodGet, path+"?per_page=1", nil)
+	if err != nil && strings.HasSuffix(path, "/usage") && usageRetired(err) {
+		check.Status = CheckWarning
+		check.Detail = fmt.Sprintf("GET %s answered HTTP %d, the legacy endpoint is retired and the metrics are used instead", path, httpStatus(err))
+		return check
+	}
+	if err != nil {
+		check.Status, check.Detail = CheckFailed, notFound(path, err).Error()
+		return check
+	}
+	resp.Body.Close()
+	check.Status, check.Detail = CheckPassed, fmt.Sprintf("GET %s answered %s", path, resp.Status)
+	return check
+}
+
+// scopeEndpoints lists the endpoints insights and seats are read from
+func scopeEndpoints(scope Scope) [][2]string {
+	endpoints := [][2]string{
+		{"Usage endpoint is accessible", scope.endpoint() + "/usage"},
+		{"Metrics endpoint is accessible", scope.endpoint() + "/metrics"},
+	}
+	switch {
+	case scope.Team == "":
+		endpoints = append(endpoints, [2]string{"Seats endpoint is accessible", scope.endpoint() + "/billing/seats"})
+	case scope.Type == "enterprises":
+		endpoints = append(endpoints, [2]string{"Team members are accessible", fmt.Sprintf("enterprises/%s/teams/%s/memberships", scope.Name, scope.Team)})
+	default:
+		endpoints = append(endpoints, [2]string{"Team members are accessible", fmt.Sprintf("orgs/%s/teams/%s/members", scope.Name, scope.Team)})
+	}
+	return endpoints
+}
+
+// Diagnose checks the gh authentication, the token scopes, the host, and when scopeName is given the scope
+// and each of its Copilot endpoints. Checks that depend on a failed one are skipped.
+func Diagnose(ctx context.Context, scopeName, teamName string) []Check {
+	host := currentHost()
+	var checks []Check
+
+	authenticated := true
+	token, source := auth.TokenForHost(host)
+	switch {
+	case token != "":
+		checks = append(checks, Check{Name: "gh is authenticated", Status: CheckPassed, Detail: fmt.Sprintf("token for %s from %s", host, source)})
+	case isLocalHost(host):
+		checks = append(checks, Check{Name: "gh is authenticated", Status: CheckSkipped, Detail: "local hosts don't need a token"})
+	default:
+		authenticated = false
+		checks = append(checks, Check{Name: "gh is authenticated", Status: CheckFailed, Detail: fmt.Sprintf("no token for %s, log in with: gh auth login -h %s", host, host)})
+	}
+
+	reachability := checkReachability(ctx, host)
+	checks = append(checks, reachability)
+
+	var client api.RESTClient
+	if authenticated && reachability.Status == CheckPassed {
+		var err error
+		if client, err = getRESTClient(); err != nil {
+			checks = append(checks, Check{Name: "API client", Status: CheckFailed, Detail: err.Error()})
+		}
+	}
+	skip := func(names ...string) {
+		for _, name := range names {
+			checks = append(checks, Check{Name: name, Status: CheckSkipped, Detail: "an earlier check failed"})
+		}
+	}
+
+	// Probe instead of trusting the cache, a stale entry is one of the things to find out about. The type is
+	// needed first since organizations and enterprises accept different token scopes.
+	var scopeType string
+	var scopeErr error
+	if client != nil && scopeName != "" {
+		scopeType, scopeErr = probeScopeType(ctx, client, scopeName)
+	}
+
+	if client == nil {
+		skip("Token scopes")
+	} else {
+		checks = append(checks, checkTokenScopes(ctx, client, scopeType)...)
+	}
+
+	scopeCheck := fmt.Sprintf("Scope %s is resolved", scopeName)
+	switch {
+	case scopeName == "":
+		checks = append(checks, Check{Name: "Scope is resolved", Status: CheckSkipped, Detail: "pass --scope to check a scope and its endpoints"})
+		return checks
+	case client == nil:
+		skip(scopeCheck)
+		return checks
+	case scopeErr != nil:
+		checks = append(checks, Check{Name: scopeCheck, Status: CheckFailed, Detail: scopeErr.Error()})
+		return checks
+	}
+
+	kind := map[string]string{"orgs": "an organization", "enterprises": "an enterprise"}[scopeType]
+	checks = append(checks, Check{Name: scopeCheck, Status: CheckPassed, Detail: fmt.Sprintf("%s is %s", scopeName, kind)})
+	for _, endpoint := range scopeEndpoints(Scope{Type: scopeType, Name: scopeName, Team: teamName}) {
+		checks = append(checks, checkEndpoint(ctx, client, endpoint[0], endpoint[1]))
+	}
+	return checks
+}
+
+// Healthy reports whether none of the checks failed
+func Healthy(checks []Check) bool {
+	for _, check := range checks {
+		if check.Status == CheckFailed {
+			return false
+		}
+	}
+	return true
+}
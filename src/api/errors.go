@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/pkg/api"
+)
+
+// ScopeNotFoundError is returned when the organization, enterprise, or team does not exist or the token
+// cannot see it, GitHub answers both with a 404
+type ScopeNotFoundError struct {
+	Scope string
+	Err   error
+}
+
+func (e *ScopeNotFoundError) Error() string {
+	return fmt.Sprintf("%s was not found or is not visible to the token", e.Scope)
+}
+
+func (e *ScopeNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// PermissionError is returned when the token is rejected or lacks the OAuth scopes or role an endpoint needs
+type PermissionError struct {
+	Path string
+	// StatusCode is 401 when the token itself was rejected, otherwise 403
+	StatusCode int
+	Message    string
+	// Accepted lists the OAuth scopes the endpoint accepts, Granted those of the token, both may be empty
+	Accepted []string
+	Granted  []string
+	Err      error
+}
+
+func (e *PermissionError) Error() string {
+	reason := fmt.Sprintf("access to %s was denied: %s", e.Path, e.Message)
+	if missing := e.Missing(); len(missing) > 0 {
+		reason += fmt.Sprintf(" (the token has none of the scopes %s)", strings.Join(missing, ", "))
+	}
+	return reason
+}
+
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
+
+// Missing returns the accepted scopes the token was not granted, empty when none of them is known
+func (e *PermissionError) Missing() []string {
+	if len(e.Accepted) == 0 {
+		return nil
+	}
+	granted := make(map[string]bool)
+	for _, scope := range e.Granted {
+		granted[scope] = true
+	}
+	var missing []string
+	for _, scope := range e.Accepted {
+		if granted[scope] {
+			return nil
+		}
+		missing = append(missing, scope)
+	}
+	return missing
+}
+
+// NotOrganizationError is returned when seats are managed in an enterprise, which only organizations support
+type NotOrganizationError struct {
+	Scope string
+}
+
+func (e *NotOrganizationError) Error() string {
+	return fmt.Sprintf("seat management is only supported for organizations, %s is an enterprise", e.Scope)
+}
+
+// MetricsDisabledError is returned when the "Copilot metrics API access" policy of the scope is disabled
+type MetricsDisabledError struct {
+	Path    string
+	Message string
+	Err     error
+}
+
+func (e *MetricsDisabledError) Error() string {
+	return fmt.Sprintf("the Copilot metrics API is disabled for %s: %s", e.Path, e.Message)
+}
+
+func (e *MetricsDisabledError) Unwrap() error {
+	return e.Err
+}
+
+// InsufficientDataError is returned when there are no metrics within the window, GitHub reports none for
+// scopes with fewer than five active Copilot users
+type InsufficientDataError struct {
+	Scope string
+}
+
+func (e *InsufficientDataError) Error() string {
+	return fmt.Sprintf("no Copilot metrics are available for %s", e.Scope)
+}
+
+// RateLimitError is returned once retries gave up on a rate limited request
+type RateLimitError struct {
+	Path string
+	// Reset is when the limit resets, zero if GitHub did not say
+	Reset time.Time
+	Err   error
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return fmt.Sprintf("the request to %s was rate limited", e.Path)
+	}
+	return fmt.Sprintf("the request to %s was rate limited until %s", e.Path, e.Reset.Local().Format("15:04:05"))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+func splitScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func rateLimitReset(headers http.Header) time.Time {
+	if seconds, err := strconv.Atoi(headers.Get("Retry-After")); err == nil {
+		return time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	if epoch, err := strconv.ParseInt(headers.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		return time.Unix(epoch, 0)
+	}
+	return time.Time{}
+}
+
+// classifyError turns the HTTP errors GitHub answers with into the typed errors above. 404s are left as is,
+// as callers such as the scope probes expect them, see notFound.
+func classifyError(path string, err error) error {
+	var httpErr api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+	message := strings.ToLower(httpErr.Message)
+
+	switch {
+	case httpErr.StatusCode == http.StatusTooManyRequests,
+		httpErr.StatusCode == http.StatusForbidden && (httpErr.Headers.Get("X-RateLimit-Remaining") == "0" || strings.Contains(message, "rate limit")):
+		return &RateLimitError{Path: path, Reset: rateLimitReset(httpErr.Headers), Err: err}
+	case (httpErr.StatusCode == http.StatusForbidden || httpErr.StatusCode == http.StatusUnprocessableEntity) &&
+		strings.Contains(message, "copilot") && strings.Contains(message, "disabled"):
+		return &MetricsDisabledError{Path: path, Message: httpErr.Message, Err: err}
+	case httpErr.StatusCode == http.StatusUnauthorized, httpErr.StatusCode == http.StatusForbidden:
+		return &PermissionError{
+			Path:       path,
+			StatusCode: httpErr.StatusCode,
+			Message:    httpErr.Message,
+			Accepted:   splitScopes(httpErr.Headers.Get("X-Accepted-OAuth-Scopes")),
+			Granted:    splitScopes(httpErr.Headers.Get("X-OAuth-Scopes")),
+			Err:        err,
+		}
+	}
+	return err
+}
+
+// notFound reports a 404 of a scope's endpoint as a ScopeNotFoundError
+func notFound(scopeName string, err error) error {
+	if httpStatus(err) == http.StatusNotFound {
+		return &ScopeNotFoundError{Scope: scopeName, Err: err}
+	}
+	return err
+}
+
+// IsTransient reports whether a retry may succeed, as for network failures and server errors
+func IsTransient(err error) bool {
+	if status := httpStatus(err); status != 0 {
+		return status >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
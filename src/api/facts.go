@@ -0,0 +1,165 @@
+package api
+
+// Features of the metrics, as named in the raw facts
+const (
+	FeatureTotal              = "total"
+	FeatureIDECodeCompletions = "ide_code_completions"
+	FeatureIDEChat            = "ide_chat"
+	FeatureDotcomChat         = "dotcom_chat"
+	FeatureDotcomPullRequests = "dotcom_pull_requests"
+	FeatureLegacyUsage        = "legacy_usage"
+)
+
+// Fact is a single counter of the downloaded data, with its path through the metrics tree flattened into
+// fields, so it can be loaded as a table without knowing the schema
+type Fact struct {
+	Date          string `json:"date"`
+	Feature       string `json:"feature"`
+	Editor        string `json:"editor,omitempty"`
+	Repository    string `json:"repository,omitempty"`
+	Model         string `json:"model,omitempty"`
+	IsCustomModel bool   `json:"is_custom_model,omitempty"`
+	Language      string `json:"language,omitempty"`
+	Metric        string `json:"metric"`
+	Value         int    `json:"value"`
+}
+
+// factCollector sums facts with the same path and metric, which merged data has one of per scope
+type factCollector struct {
+	facts []Fact
+	index map[Fact]int
+}
+
+func (c *factCollector) add(fact Fact, counters map[string]int) {
+	for _, metric := range sortedCounterNames(counters) {
+		fact.Metric, fact.Value = metric, 0
+		key := fact
+		if i, ok := c.index[key]; ok {
+			c.facts[i].Value += counters[metric]
+			continue
+		}
+		c.index[key] = len(c.facts)
+		fact.Value = counters[metric]
+		c.facts = append(c.facts, fact)
+	}
+}
+
+// counterOrder keeps the counters of a fact in the order the API documents them
+var counterOrder = []string{
+	"total_active_users", "total_engaged_users",
+	"total_code_suggestions", "total_code_acceptances", "total_code_lines_suggested", "total_code_lines_accepted",
+	"total_chats", "total_chat_insertion_events", "total_chat_copy_events", "total_pr_summaries_created",
+	"total_suggestions_count", "total_acceptances_count", "total_lines_suggested", "total_lines_accepted",
+	"total_active_chat_users", "total_chat_turns", "total_chat_acceptances",
+}
+
+func sortedCounterNames(counters map[string]int) []string {
+	names := make([]string, 0, len(counters))
+	for _, name := range counterOrder {
+		if _, ok := counters[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func modelFact(fact Fact, model ModelMetrics) Fact {
+	fact.Model, fact.IsCustomModel = model.Name, model.IsCustomModel
+	return fact
+}
+
+// flattenFacts lists every counter of the metrics and the legacy usage, day by day
+func flattenFacts(usage []CopilotUsage, metrics []CopilotMetrics) []Fact {
+	c := &factCollector{index: make(map[Fact]int)}
+	for _, m := range metrics {
+		day := Fact{Date: m.Date}
+
+		total := day
+		total.Feature = FeatureTotal
+		c.add(total, map[string]int{"total_active_users": m.TotalActiveUsers, "total_engaged_users": m.TotalEngagedUsers})
+
+		completions := day
+		completions.Feature = FeatureIDECodeCompletions
+		c.add(completions, map[string]int{"total_engaged_users": m.CopilotIDECodeCompletions.TotalEngagedUsers})
+		for _, language := range m.CopilotIDECodeCompletions.Languages {
+			fact := completions
+			fact.Language = language.Name
+			c.add(fact, map[string]int{"total_engaged_users": language.TotalEngagedUsers})
+		}
+		for _, editor := range m.CopilotIDECodeCompletions.Editors {
+			editorFact := completions
+			editorFact.Editor = editor.Name
+			c.add(editorFact, map[string]int{"total_engaged_users": editor.TotalEngagedUsers})
+			for _, model := range editor.Models {
+				fact := modelFact(editorFact, model)
+				c.add(fact, map[string]int{"total_engaged_users": model.TotalEngagedUsers})
+				for _, language := range model.Languages {
+					fact.Language = language.Name
+					c.add(fact, map[string]int{
+						"total_engaged_users":        language.TotalEngagedUsers,
+						"total_code_suggestions":     language.TotalCodeSuggestions,
+						"total_code_acceptances":     language.TotalCodeAcceptances,
+						"total_code_lines_suggested": language.TotalCodeLinesSuggested,
+						"total_code_lines_accepted":  language.TotalCodeLinesAccepted,
+					})
+				}
+			}
+		}
+
+		chat := day
+		chat.Feature = FeatureIDEChat
+		c.add(chat, map[string]int{"total_engaged_users": m.CopilotIDEChat.TotalEngagedUsers})
+		for _, editor := range m.CopilotIDEChat.Editors {
+			editorFact := chat
+			editorFact.Editor = editor.Name
+			c.add(editorFact, map[string]int{"total_engaged_users": editor.TotalEngagedUsers})
+			for _, model := range editor.Models {
+				c.add(modelFact(editorFact, model), map[string]int{
+					"total_engaged_users":         model.TotalEngagedUsers,
+					"total_chats":                 model.TotalChats,
+					"total_chat_insertion_events": model.TotalChatInsertionEvents,
+					"total_chat_copy_events":      model.TotalChatCopyEvents,
+				})
+			}
+		}
+
+		dotcomChat := day
+		dotcomChat.Feature = FeatureDotcomChat
+		c.add(dotcomChat, map[string]int{"total_engaged_users": m.CopilotDotcomChat.TotalEngagedUsers})
+		for _, model := range m.CopilotDotcomChat.Models {
+			c.add(modelFact(dotcomChat, model), map[string]int{
+				"total_engaged_users": model.TotalEngagedUsers,
+				"total_chats":         model.TotalChats,
+			})
+		}
+
+		pullRequests := day
+		pullRequests.Feature = FeatureDotcomPullRequests
+		c.add(pullRequests, map[string]int{"total_engaged_users": m.CopilotDotcomPullRequests.TotalEngagedUsers})
+		for _, repository := range m.CopilotDotcomPullRequests.Repositories {
+			repositoryFact := pullRequests
+			repositoryFact.Repository = repository.Name
+			c.add(repositoryFact, map[string]int{"total_engaged_users": repository.TotalEngagedUsers})
+			for _, model := range repository.Models {
+				c.add(modelFact(repositoryFact, model), map[string]int{
+					"total_engaged_users":        model.TotalEngagedUsers,
+					"total_pr_summaries_created": model.TotalPRSummariesCreated,
+				})
+			}
+		}
+	}
+
+	for _, u := range usage {
+		c.add(Fact{Date: u.Day, Feature: FeatureLegacyUsage}, map[string]int{
+			"total_active_users":      u.TotalActiveUsers,
+			"total_suggestions_count": u.TotalSuggestionsCount,
+			"total_acceptances_count": u.TotalAcceptancesCount,
+			"total_lines_suggested":   u.TotalLinesSuggested,
+			"total_lines_accepted":    u.TotalLinesAccepted,
+			"total_active_chat_users": u.TotalActiveChatUsers,
+			"total_chat_turns":        u.TotalChatTurns,
+			"total_chat_acceptances":  u.TotalChatAcceptances,
+		})
+	}
+	return c.facts
+}
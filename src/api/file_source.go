@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// fileSource reads exported API responses from a directory, so insights can be computed offline. The
+// directory holds metrics.json and optionally usage.json and billing.json, either directly or in a
+// subdirectory per scope (<scope>/ or <scope>/<team>/).
+type fileSource struct {
+	dir string
+	// shared lets scopes without a team and without a subdirectory read the files of dir itself
+	shared bool
+}
+
+// NewFileSource reads the exports in dir. Unless shared is set, every scope needs its own subdirectory, so
+// that the data of one scope is never reported for another.
+func NewFileSource(dir string, shared bool) DataSource {
+	return fileSource{dir: dir, shared: shared}
+}
+
+// scopeDir returns the directory of the scope, a team only reads <scope>/<team>/
+func (s fileSource) scopeDir(scope Scope) (string, error) {
+	dir := filepath.Join(s.dir, scope.Name)
+	if scope.Team != "" {
+		dir = filepath.Join(dir, scope.Team)
+	}
+	info, err := os.Stat(dir)
+	switch {
+	case err == nil && info.IsDir():
+		return dir, nil
+	case scope.Team == "" && s.shared:
+		return s.dir, nil
+	case err == nil:
+		err = &os.PathError{Op: "open", Path: dir, Err: syscall.ENOTDIR}
+	}
+	return "", err
+}
+
+// readJSON decodes a file of the scope directory, returning false when the file doesn't exist
+func (s fileSource) readJSON(scope Scope, name string, v interface{}) (bool, error) {
+	dir, err := s.scopeDir(scope)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(dir, name)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Debugf("No %s found in %s", name, filepath.Dir(path))
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	logger.Debugf("Reading %s", path)
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("%s: %w", path, err)
+	}
+	return true, nil
+}
+
+// ScopeType defaults to an organization, exports don't record the type so --scope-type sets it
+func (s fileSource) ScopeType(ctx context.Context, scopeName string) (string, error) {
+	if scopeType, ok := explicitScopeTypes[strings.ToLower(scopeName)]; ok {
+		return scopeType, nil
+	}
+	return "orgs", nil
+}
+
+func (s fileSource) Usage(ctx context.Context, scope Scope, window DateRange) ([]CopilotUsage, error) {
+	var usage []CopilotUsage
+	_, err := s.readJSON(scope, "usage.json", &usage)
+	return usage, err
+}
+
+func (s fileSource) Metrics(ctx context.Context, scope Scope, window DateRange) ([]CopilotMetrics, error) {
+	var metrics []CopilotMetrics
+	found, err := s.readJSON(scope, "metrics.json", &metrics)
+	if err == nil && !found {
+		dir, _ := s.scopeDir(scope)
+		err = &os.PathError{Op: "open", Path: filepath.Join(dir, "metrics.json"), Err: os.ErrNotExist}
+	}
+	return metrics, err
+}
+
+func (s fileSource) Billing(ctx context.Context, scope Scope) (CopilotBilling, error) {
+	var billing CopilotBilling
+	_, err := s.readJSON(scope, "billing.json", &billing)
+	return billing, err
+}
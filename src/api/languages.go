@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Orders of the language breakdown, see NewLanguageBreakdown
+const (
+	SortLanguagesByUsers       = "users"
+	SortLanguagesByAcceptance  = "acceptance"
+	SortLanguagesByEfficiency  = "efficiency"
+	SortLanguagesBySuggestions = "suggestions"
+	SortLanguagesByName        = "name"
+)
+
+// LanguageBreakdown selects and orders the languages of the breakdown by language
+type LanguageBreakdown struct {
+	// languages limits the breakdown to these lower-cased names, empty means every language
+	languages map[string]bool
+	sortBy    string
+}
+
+// NewLanguageBreakdown breaks the insights down by language, limited to languages unless it is empty and
+// ordered by sortBy. The most used, accepted or efficient languages come first, names are sorted A to Z.
+func NewLanguageBreakdown(languages []string, sortBy string) (*LanguageBreakdown, error) {
+	switch sortBy {
+	case SortLanguagesByUsers, SortLanguagesByAcceptance, SortLanguagesByEfficiency, SortLanguagesBySuggestions, SortLanguagesByName:
+	default:
+		return nil, fmt.Errorf("invalid language order %q, use '%s', '%s', '%s', '%s', or '%s'", sortBy,
+			SortLanguagesByUsers, SortLanguagesByAcceptance, SortLanguagesByEfficiency, SortLanguagesBySuggestions, SortLanguagesByName)
+	}
+	breakdown := &LanguageBreakdown{languages: make(map[string]bool), sortBy: sortBy}
+	for _, language := range languages {
+		breakdown.languages[strings.ToLower(language)] = true
+	}
+	return breakdown, nil
+}
+
+// LanguageInsight holds the code completion metrics of one language
+type LanguageInsight struct {
+	Name string `json:"name"`
+	// EngagedUsers is the average number of users engaged with completions in the language per day
+	EngagedUsers           float64 `json:"engaged_users"`
+	Suggestions            int     `json:"suggestions"`
+	Acceptances            int     `json:"acceptances"`
+	LinesSuggested         int     `json:"lines_suggested"`
+	LinesAccepted          int     `json:"lines_accepted"`
+	AcceptanceRate         Metric  `json:"acceptance_rate"`
+	LineAdoptionEfficiency Metric  `json:"line_adoption_efficiency"`
+}
+
+// getLanguageInsights sums the editors → models → languages tree of the code completions per language, engaged
+// users come from the per-language totals as users of several editors would be counted twice in the tree
+func getLanguageInsights(metrics []CopilotMetrics) []LanguageInsight {
+	byName := make(map[string]*LanguageInsight)
+	var names []string
+	language := func(name string) *LanguageInsight {
+		key := strings.ToLower(name)
+		if l, ok := byName[key]; ok {
+			return l
+		}
+		l := &LanguageInsight{Name: name}
+		byName[key] = l
+		names = append(names, key)
+		return l
+	}
+
+	for _, m := range metrics {
+		for _, l := range m.CopilotIDECodeCompletions.Languages {
+			language(l.Name).EngagedUsers += float64(l.TotalEngagedUsers)
+		}
+		for _, editor := range m.CopilotIDECodeCompletions.Editors {
+			for _, model := range editor.Models {
+				for _, l := range model.Languages {
+					insight := language(l.Name)
+					insight.Suggestions += l.TotalCodeSuggestions
+					insight.Acceptances += l.TotalCodeAcceptances
+					insight.LinesSuggested += l.TotalCodeLinesSuggested
+					insight.LinesAccepted += l.TotalCodeLinesAccepted
+				}
+			}
+		}
+	}
+
+	insights := make([]LanguageInsight, 0, len(names))
+	for _, key := range names {
+		l := byName[key]
+		l.EngagedUsers = ratio(l.EngagedUsers, float64(len(metrics)))
+		l.AcceptanceRate = Metric{
+			Value:       ratio(float64(l.Acceptances), float64(l.Suggestions)),
+			DisplayName: "Acceptance Rate",
+			Description: "Share of the suggestions in this language that were accepted. Calculated as Acceptances / Suggestions.",
+			Category:    "Languages",
+		}
+		l.LineAdoptionEfficiency = Metric{
+			Value:       ratio(float64(l.LinesAccepted), float64(l.LinesSuggested)),
+			DisplayName: "Line Adoption Efficiency",
+			Description: "Share of the suggested lines in this language that were accepted. Calculated as Lines Accepted / Lines Suggested.",
+			Category:    "Languages",
+		}
+		insights = append(insights, *l)
+	}
+	return insights
+}
+
+// apply filters and orders the languages
+func (breakdown LanguageBreakdown) apply(languages []LanguageInsight) []LanguageInsight {
+	filtered := make([]LanguageInsight, 0, len(languages))
+	for _, l := range languages {
+		if len(breakdown.languages) == 0 || breakdown.languages[strings.ToLower(l.Name)] {
+			filtered = append(filtered, l)
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		switch breakdown.sortBy {
+		case SortLanguagesByAcceptance:
+			return a.AcceptanceRate.Value > b.AcceptanceRate.Value
+		case SortLanguagesByEfficiency:
+			return a.LineAdoptionEfficiency.Value > b.LineAdoptionEfficiency.Value
+		case SortLanguagesBySuggestions:
+			return a.Suggestions > b.Suggestions
+		case SortLanguagesByName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		default:
+			return a.EngagedUsers > b.EngagedUsers
+		}
+	})
+	return filtered
+}
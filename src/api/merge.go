@@ -0,0 +1,90 @@
+package api
+
+import "sort"
+
+const (
+	combinedScopeName = "all scopes"
+	combinedScopeType = "combined"
+)
+
+// mergeScopeData combines the raw data of several scopes day by day, so ratios are computed from the
+// combined counts rather than averaged. Users present in several scopes are counted once per scope.
+func mergeScopeData(data []scopeData) scopeData {
+	merged := scopeData{Name: combinedScopeName, Type: combinedScopeType}
+	var usage []CopilotUsage
+	var metrics []CopilotMetrics
+	for _, d := range data {
+		usage = append(usage, d.Usage...)
+		metrics = append(metrics, d.Metrics...)
+		merged.Billing.Total += d.Billing.Total
+		merged.Billing.Seats = append(merged.Billing.Seats, d.Billing.Seats...)
+	}
+	merged.Usage = mergeUsageByDay(usage)
+	merged.Metrics = mergeMetricsByDate(metrics)
+	return merged
+}
+
+func mergeUsageByDay(usage []CopilotUsage) []CopilotUsage {
+	byDay := make(map[string]*CopilotUsage)
+	var days []string
+	for _, u := range usage {
+		day, ok := byDay[u.Day]
+		if !ok {
+			day = &CopilotUsage{Day: u.Day}
+			byDay[u.Day] = day
+			days = append(days, u.Day)
+		}
+		day.TotalSuggestionsCount += u.TotalSuggestionsCount
+		day.TotalAcceptancesCount += u.TotalAcceptancesCount
+		day.TotalLinesSuggested += u.TotalLinesSuggested
+		day.TotalLinesAccepted += u.TotalLinesAccepted
+		day.TotalActiveUsers += u.TotalActiveUsers
+		day.TotalChatAcceptances += u.TotalChatAcceptances
+		day.TotalChatTurns += u.TotalChatTurns
+		day.TotalActiveChatUsers += u.TotalActiveChatUsers
+	}
+
+	sort.Strings(days)
+	merged := make([]CopilotUsage, 0, len(days))
+	for _, day := range days {
+		merged = append(merged, *byDay[day])
+	}
+	return merged
+}
+
+// mergeMetricsByDate sums the counters of each day, the nested editor, language and repository breakdowns
+// are concatenated as they are aggregated by name later on
+func mergeMetricsByDate(metrics []CopilotMetrics) []CopilotMetrics {
+	byDate := make(map[string]*CopilotMetrics)
+	var dates []string
+	for _, m := range metrics {
+		day, ok := byDate[m.Date]
+		if !ok {
+			day = &CopilotMetrics{Date: m.Date}
+			byDate[m.Date] = day
+			dates = append(dates, m.Date)
+		}
+		day.TotalActiveUsers += m.TotalActiveUsers
+		day.TotalEngagedUsers += m.TotalEngagedUsers
+
+		day.CopilotIDEChat.TotalEngagedUsers += m.CopilotIDEChat.TotalEngagedUsers
+		day.CopilotIDEChat.Editors = append(day.CopilotIDEChat.Editors, m.CopilotIDEChat.Editors...)
+
+		day.CopilotDotcomChat.TotalEngagedUsers += m.CopilotDotcomChat.TotalEngagedUsers
+		day.CopilotDotcomChat.Models = append(day.CopilotDotcomChat.Models, m.CopilotDotcomChat.Models...)
+
+		day.CopilotDotcomPullRequests.TotalEngagedUsers += m.CopilotDotcomPullRequests.TotalEngagedUsers
+		day.CopilotDotcomPullRequests.Repositories = append(day.CopilotDotcomPullRequests.Repositories, m.CopilotDotcomPullRequests.Repositories...)
+
+		day.CopilotIDECodeCompletions.TotalEngagedUsers += m.CopilotIDECodeCompletions.TotalEngagedUsers
+		day.CopilotIDECodeCompletions.Editors = append(day.CopilotIDECodeCompletions.Editors, m.CopilotIDECodeCompletions.Editors...)
+		day.CopilotIDECodeCompletions.Languages = append(day.CopilotIDECodeCompletions.Languages, m.CopilotIDECodeCompletions.Languages...)
+	}
+
+	sort.Strings(dates)
+	merged := make([]CopilotMetrics, 0, len(dates))
+	for _, date := range dates {
+		merged = append(merged, *byDate[date])
+	}
+	return merged
+}
@@ -1,7 +1,8 @@
 package api
 
 type CopilotBilling struct {
-	Total int `json:"total_seats"`
+	Total int    `json:"total_seats"`
+	Seats []Seat `json:"seats"`
 	// SeatBreakdown         SeatBreakdown `json:"seat_breakdown"`
 	// SeatManagementSetting string        `json:"seat_management_setting"`
 	// IDEChat               string        `json:"ide_chat"`
@@ -20,6 +21,35 @@ type CopilotBilling struct {
 // 	InactiveThisCycle   int `json:"inactive_this_cycle"`
 // }
 
+type Seat struct {
+	Assignee                SeatAssignee       `json:"assignee"`
+	AssigningTeam           *SeatAssigningTeam `json:"assigning_team"`
+	Organization            *SeatOrganization  `json:"organization"`
+	CreatedAt               string             `json:"created_at"`
+	UpdatedAt               string             `json:"updated_at"`
+	PendingCancellationDate string             `json:"pending_cancellation_date"`
+	LastActivityAt          string             `json:"last_activity_at"`
+	LastActivityEditor      string             `json:"last_activity_editor"`
+	PlanType                string             `json:"plan_type"`
+}
+
+type SeatAssignee struct {
+	Login string `json:"login"`
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+}
+
+type SeatAssigningTeam struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	ID   int    `json:"id"`
+}
+
+type SeatOrganization struct {
+	Login string `json:"login"`
+	ID    int    `json:"id"`
+}
+
 type CopilotMetrics struct {
 	Date                      string                `json:"date"`
 	CopilotIDEChat            IDEChatMetrics        `json:"copilot_ide_chat"`
@@ -41,13 +71,18 @@ type EditorMetrics struct {
 	TotalEngagedUsers int            `json:"total_engaged_users"`
 }
 
+// ModelMetrics is shared by every feature of the metrics, each only reports the counters that apply to it:
+// chats for IDE and dotcom chat, copy and insertion events for IDE chat, languages for code completions, and
+// PR summaries for pull requests
 type ModelMetrics struct {
 	Name                     string            `json:"name"`
 	TotalChats               int               `json:"total_chats"`
 	IsCustomModel            bool              `json:"is_custom_model"`
+	CustomModelTrainingDate  *string           `json:"custom_model_training_date,omitempty"`
 	TotalEngagedUsers        int               `json:"total_engaged_users"`
 	TotalChatCopyEvents      int               `json:"total_chat_copy_events"`
 	TotalChatInsertionEvents int               `json:"total_chat_insertion_events"`
+	TotalPRSummariesCreated  int               `json:"total_pr_summaries_created,omitempty"`
 	Languages                []LanguageMetrics `json:"languages"`
 }
 
@@ -61,7 +96,8 @@ type LanguageMetrics struct {
 }
 
 type DotcomChatMetrics struct {
-	TotalEngagedUsers int `json:"total_engaged_users"`
+	Models            []ModelMetrics `json:"models"`
+	TotalEngagedUsers int            `json:"total_engaged_users"`
 }
 
 type PullRequestMetrics struct {
@@ -96,11 +132,17 @@ type CopilotUsage struct {
 type Insight struct {
 	ScopeName            string                      `json:"scope_name"`
 	ScopeType            string                      `json:"scope_type"`
+	Window               DateRange                   `json:"window"`
+	PreviousWindow       *DateRange                  `json:"previous_window,omitempty"`
+	CompletionsSource    string                      `json:"completions_source"`
 	AdoptionUtilization  AdoptionUtilizationMetrics  `json:"adoption_utilization"`
 	ProductivityImpact   ProductivityImpactMetrics   `json:"productivity_impact"`
 	ROICostEfficiency    ROICostEfficiencyMetrics    `json:"roi_cost_efficiency"`
 	WorkflowAcceleration WorkflowAccelerationMetrics `json:"workflow_acceleration"`
 	StrategicGrowth      StrategicGrowthMetrics      `json:"strategic_growth"`
+	Languages            []LanguageInsight           `json:"languages,omitempty"`
+	Series               *InsightSeries              `json:"series,omitempty"`
+	RawFacts             []Fact                      `json:"raw_facts,omitempty"`
 }
 
 type AdoptionUtilizationMetrics struct {
@@ -112,9 +154,11 @@ type AdoptionUtilizationMetrics struct {
 }
 
 type ProductivityImpactMetrics struct {
-	CodeAcceptanceRate     Metric `json:"code_acceptance_rate"`
-	CodeAdoptionEfficiency Metric `json:"code_adoption_efficiency"`
-	AIChatEngagement       Metric `json:"ai_chat_engagement"`
+	CodeAcceptanceRate           Metric            `json:"code_acceptance_rate"`
+	CodeAdoptionEfficiency       Metric            `json:"code_adoption_efficiency"`
+	AIChatEngagement             Metric            `json:"ai_chat_engagement"`
+	EditorAcceptanceRate         map[string]Metric `json:"editor_acceptance_rate"`
+	EditorLineAdoptionEfficiency map[string]Metric `json:"editor_line_adoption_efficiency"`
 }
 
 type ROICostEfficiencyMetrics struct {
@@ -137,4 +181,6 @@ type Metric struct {
 	DisplayName string  `json:"display_name"`
 	Description string  `json:"description"`
 	Category    string  `json:"category"`
+	// Previous is the value in the previous window when comparing, see Compare
+	Previous *float64 `json:"previous,omitempty"`
 }
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/pkg/api"
+	logger "github.com/sirupsen/logrus"
+)
+
+const perPage = 100
+
+var linkNextRE = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func nextPage(resp *http.Response) string {
+	for _, link := range resp.Header.Values("Link") {
+		if match := linkNextRE.FindStringSubmatch(link); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+func withPerPage(path string) string {
+	if strings.Contains(path, "?") {
+		return path + "&per_page=" + strconv.Itoa(perPage)
+	}
+	return path + "?per_page=" + strconv.Itoa(perPage)
+}
+
+// getPaginated follows the Link headers starting at path, decoding each page with decode
+func getPaginated(ctx context.Context, client api.RESTClient, path string, decode func(*json.Decoder) error) error {
+	next := withPerPage(path)
+	for page := 1; next != ""; page++ {
+		logger.Debugf("Fetching page %d of %s", page, path)
+		resp, err := client.RequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return err
+		}
+		err = decode(json.NewDecoder(resp.Body))
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		next = nextPage(resp)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cli/go-gh/pkg/api"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	ReasonInactive          = "inactive"
+	ReasonNeverActive       = "never_active"
+	ReasonPendingInvitation = "pending_invitation"
+)
+
+type Invitation struct {
+	Login     string `json:"login"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ReclaimPolicy decides which seats are reclaimed, a zero day threshold disables that rule
+type ReclaimPolicy struct {
+	InactiveDays          int
+	NeverActiveDays       int
+	PendingInvitationDays int
+	Protected             map[string]bool
+}
+
+type ReclaimCandidate struct {
+	Login                 string `json:"login"`
+	Reason                string `json:"reason"`
+	AssignmentSource      string `json:"assignment_source"`
+	LastActivityAt        string `json:"last_activity_at"`
+	DaysSinceLastActivity *int   `json:"days_since_last_activity"`
+	SkipReason            string `json:"skip_reason,omitempty"`
+}
+
+type ReclaimPlan struct {
+	Organization string             `json:"organization"`
+	Remove       []ReclaimCandidate `json:"remove"`
+	Skipped      []ReclaimCandidate `json:"skipped"`
+}
+
+func (p ReclaimPlan) Logins() []string {
+	logins := make([]string, 0, len(p.Remove))
+	for _, candidate := range p.Remove {
+		logins = append(logins, candidate.Login)
+	}
+	return logins
+}
+
+func daysSince(timestamp string, now time.Time) (int, bool) {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return 0, false
+	}
+	return int(now.Sub(t).Hours() / 24), true
+}
+
+func reclaimReason(seat Seat, pending map[string]Invitation, policy ReclaimPolicy, now time.Time) string {
+	if invitation, ok := pending[strings.ToLower(seat.Assignee.Login)]; ok && policy.PendingInvitationDays > 0 {
+		if days, ok := daysSince(invitation.CreatedAt, now); ok && days >= policy.PendingInvitationDays {
+			return ReasonPendingInvitation
+		}
+	}
+
+	if seat.LastActivityAt == "" {
+		// Give new seats a grace period before treating them as never used
+		if days, ok := daysSince(seat.CreatedAt, now); ok && policy.NeverActiveDays > 0 && days >= policy.NeverActiveDays {
+			return ReasonNeverActive
+		}
+		return ""
+	}
+
+	if days := seat.DaysSinceLastActivity(now); days != nil && policy.InactiveDays > 0 && *days >= policy.InactiveDays {
+		return ReasonInactive
+	}
+	return ""
+}
+
+// PlanReclamation selects the seats to reclaim. Seats already pending cancellation, protected users and seats
+// granted through a team are reported as skipped, team seats can only be removed by changing the team membership.
+func PlanReclamation(organization string, seats []Seat, invitations []Invitation, policy ReclaimPolicy, now time.Time) ReclaimPlan {
+	pending := make(map[string]Invitation)
+	for _, invitation := range invitations {
+		if invitation.Login != "" {
+			pending[strings.ToLower(invitation.Login)] = invitation
+		}
+	}
+
+	plan := ReclaimPlan{Organization: organization, Remove: []ReclaimCandidate{}, Skipped: []ReclaimCandidate{}}
+	for _, seat := range seats {
+		reason := reclaimReason(seat, pending, policy, now)
+		if reason == "" {
+			continue
+		}
+
+		candidate := ReclaimCandidate{
+			Login:                 seat.Assignee.Login,
+			Reason:                reason,
+			AssignmentSource:      seat.AssignmentSource(),
+			LastActivityAt:        seat.LastActivityAt,
+			DaysSinceLastActivity: seat.DaysSinceLastActivity(now),
+		}
+		switch {
+		case seat.PendingCancellationDate != "":
+			candidate.SkipReason = fmt.Sprintf("cancellation already pending for %s", seat.PendingCancellationDate)
+		case policy.Protected[strings.ToLower(seat.Assignee.Login)]:
+			candidate.SkipReason = "protected"
+		case seat.AssigningTeam != nil:
+			candidate.SkipReason = fmt.Sprintf("assigned through team %s", seat.AssigningTeam.Slug)
+		}
+
+		if candidate.SkipReason != "" {
+			plan.Skipped = append(plan.Skipped, candidate)
+		} else {
+			plan.Remove = append(plan.Remove, candidate)
+		}
+	}
+	return plan
+}
+
+// getOrganizationClient returns a client for scopes that are organizations, seat management is not
+// available for enterprises
+func getOrganizationClient(ctx context.Context, scopeName string) (api.RESTClient, error) {
+	client, err := getRESTClient()
+	if err != nil {
+		logger.Debugf("Error creating REST client: %v", err)
+		return nil, err
+	}
+
+	scopeType, err := determineEndpoint(ctx, client, scopeName)
+	if err != nil {
+		logger.Debugf("Error determining endpoint for scope %s: %v", scopeName, err)
+		return nil, err
+	}
+	if scopeType != "orgs" {
+		return nil, &NotOrganizationError{Scope: scopeName}
+	}
+	return client, nil
+}
+
+// RequireOrganization checks that the scope is an organization before any seat is read or changed
+func RequireOrganization(ctx context.Context, scopeName string) error {
+	_, err := getOrganizationClient(ctx, scopeName)
+	return err
+}
+
+func FetchPendingInvitations(ctx context.Context, organization string) ([]Invitation, error) {
+	client, err := getOrganizationClient(ctx, organization)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitations []Invitation
+	err = getPaginated(ctx, client, fmt.Sprintf("orgs/%s/invitations", organization), func(d *json.Decoder) error {
+		var page []Invitation
+		if err := d.Decode(&page); err != nil {
+			return err
+		}
+		invitations = append(invitations, page...)
+		return nil
+	})
+	if err != nil {
+		logger.Debugf("Error fetching invitations for %s: %v", organization, err)
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// CancelCopilotSeats removes the seats of the given users and returns how many were cancelled
+func CancelCopilotSeats(ctx context.Context, organization string, logins []string) (int, error) {
+	client, err := getOrganizationClient(ctx, organization)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := json.Marshal(map[string][]string{"selected_usernames": logins})
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		SeatsCancelled int `json:"seats_cancelled"`
+	}
+	err = client.DoWithContext(ctx, http.MethodDelete, fmt.Sprintf("orgs/%s/copilot/billing/selected_users", organization), bytes.NewReader(body), &response)
+	if err != nil {
+		logger.Debugf("Error cancelling seats in %s: %v", organization, err)
+		return 0, err
+	}
+	return response.SeatsCancelled, nil
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlanReclamation(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) string {
+		return now.AddDate(0, 0, -days).Format(time.RFC3339)
+	}
+	seat := func(login string, createdDaysAgo, activeDaysAgo int) Seat {
+		s := Seat{Assignee: SeatAssignee{Login: login}, CreatedAt: daysAgo(createdDaysAgo)}
+		if activeDaysAgo >= 0 {
+			s.LastActivityAt = daysAgo(activeDaysAgo)
+		}
+		return s
+	}
+	defaultPolicy := ReclaimPolicy{InactiveDays: 90, NeverActiveDays: 30, PendingInvitationDays: 30}
+
+	tests := []struct {
+		name        string
+		seats       []Seat
+		invitations []Invitation
+		policy      ReclaimPolicy
+		remove      map[string]string
+		skipped     map[string]string
+	}{
+		{
+			name:   "inactive seat is removed",
+			seats:  []Seat{seat("idle", 400, 120), seat("busy", 400, 3)},
+			policy: defaultPolicy,
+			remove: map[string]string{"idle": ReasonInactive},
+		},
+		{
+			name:   "inactivity threshold is inclusive",
+			seats:  []Seat{seat("edge", 400, 90), seat("inside", 400, 89)},
+			policy: defaultPolicy,
+			remove: map[string]string{"edge": ReasonInactive},
+		},
+		{
+			name:   "never active seat is removed after the grace period",
+			seats:  []Seat{seat("unused", 45, -1), seat("new", 10, -1)},
+			policy: defaultPolicy,
+			remove: map[string]string{"unused": ReasonNeverActive},
+		},
+		{
+			name:        "pending invitation is removed once old enough",
+			seats:       []Seat{seat("invited", 60, 1), seat("recent", 60, 1)},
+			invitations: []Invitation{{Login: "Invited", CreatedAt: daysAgo(40)}, {Login: "recent", CreatedAt: daysAgo(5)}},
+			policy:      defaultPolicy,
+			remove:      map[string]string{"invited": ReasonPendingInvitation},
+		},
+		{
+			name:    "protected user is skipped",
+			seats:   []Seat{seat("Boss", 400, 200)},
+			policy:  ReclaimPolicy{InactiveDays: 90, Protected: map[string]bool{"boss": true}},
+			skipped: map[string]string{"Boss": "protected"},
+		},
+		{
+			name: "team assigned seat is skipped",
+			seats: func() []Seat {
+				s := seat("member", 400, 200)
+				s.AssigningTeam = &SeatAssigningTeam{Slug: "platform"}
+				return []Seat{s}
+			}(),
+			policy:  defaultPolicy,
+			skipped: map[string]string{"member": "assigned through team platform"},
+		},
+		{
+			name: "seat pending cancellation is skipped",
+			seats: func() []Seat {
+				s := seat("leaving", 400, 200)
+				s.PendingCancellationDate = "2026-10-31"
+				return []Seat{s}
+			}(),
+			policy:  defaultPolicy,
+			skipped: map[string]string{"leaving": "cancellation already pending for 2026-10-31"},
+		},
+		{
+			name:        "zero thresholds disable every rule",
+			seats:       []Seat{seat("idle", 400, 300), seat("unused", 400, -1), seat("invited", 400, 1)},
+			invitations: []Invitation{{Login: "invited", CreatedAt: daysAgo(200)}},
+			policy:      ReclaimPolicy{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan := PlanReclamation("example-org", test.seats, test.invitations, test.policy, now)
+
+			remove := make(map[string]string)
+			for _, candidate := range plan.Remove {
+				remove[candidate.Login] = candidate.Reason
+			}
+			skipped := make(map[string]string)
+			for _, candidate := range plan.Skipped {
+				skipped[candidate.Login] = candidate.SkipReason
+			}
+			if test.remove == nil {
+				test.remove = map[string]string{}
+			}
+			if test.skipped == nil {
+				test.skipped = map[string]string{}
+			}
+			if !reflect.DeepEqual(remove, test.remove) {
+				t.Errorf("removed %v, want %v", remove, test.remove)
+			}
+			if !reflect.DeepEqual(skipped, test.skipped) {
+				t.Errorf("skipped %v, want %v", skipped, test.skipped)
+			}
+		})
+	}
+}
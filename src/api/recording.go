@@ -0,0 +1,229 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+var (
+	recordDir string
+	replayDir string
+)
+
+// SetRecordDir saves every API response to dir, so the run can later be replayed
+func SetRecordDir(dir string) {
+	recordDir = dir
+}
+
+// SetReplayDir serves every API request from the responses recorded in dir instead of the network
+func SetReplayDir(dir string) {
+	replayDir = dir
+}
+
+// recordedResponse is the file format of a single recorded API response
+type recordedResponse struct {
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	Query    string `json:"query"`
+	// RequestHash tells apart requests to the same endpoint with different bodies, like the pages of a
+	// GraphQL query
+	RequestHash string          `json:"request_hash,omitempty"`
+	URL         string          `json:"url"`
+	Timestamp   string          `json:"timestamp"`
+	Status      int             `json:"status"`
+	Headers     http.Header     `json:"headers"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	BodyText    string          `json:"body_text,omitempty"`
+}
+
+// recordingKey identifies a request independently of the host, GitHub Enterprise Server paths are
+// prefixed with /api/v3
+func recordingKey(method, path, query, requestHash string) string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/api/v3"), "/")
+	key := fmt.Sprintf("%s %s", method, path)
+	if query != "" {
+		key += "?" + query
+	}
+	if requestHash != "" {
+		key += " #" + requestHash
+	}
+	return key
+}
+
+// requestHash hashes the body of requests other than GET, which is empty when there is no body
+func requestHash(req *http.Request) (string, error) {
+	if req.Method == http.MethodGet || req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	var body []byte
+	var err error
+	if req.GetBody != nil {
+		var reader io.ReadCloser
+		if reader, err = req.GetBody(); err != nil {
+			return "", err
+		}
+		body, err = io.ReadAll(reader)
+		reader.Close()
+	} else {
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if err != nil || len(body) == 0 {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type recordingTransport struct {
+	dir   string
+	next  http.RoundTripper
+	mutex sync.Mutex
+	count int
+}
+
+func newRecordingTransport(dir string, next http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{dir: dir, next: next}
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hash, err := requestHash(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	endpoint := strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, "/api/v3"), "/")
+	recording := recordedResponse{
+		Method:      req.Method,
+		Endpoint:    endpoint,
+		Query:       req.URL.RawQuery,
+		RequestHash: hash,
+		URL:         req.URL.String(),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Status:      resp.StatusCode,
+		Headers:     resp.Header,
+	}
+	if json.Valid(body) {
+		recording.Body = body
+	} else {
+		recording.BodyText = string(body)
+	}
+
+	if err := t.save(recording); err != nil {
+		logger.Warnf("Error recording %s %s: %v", req.Method, endpoint, err)
+	}
+	return resp, nil
+}
+
+func (t *recordingTransport) save(recording recordedResponse) error {
+	data, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	t.count++
+	name := fmt.Sprintf("%04d-%s-%s.json", t.count, recording.Method, unsafeFileChars.ReplaceAllString(recording.Endpoint, "_"))
+	t.mutex.Unlock()
+
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return err
+	}
+	logger.Debugf("Recording %s %s to %s", recording.Method, recording.Endpoint, name)
+	return os.WriteFile(filepath.Join(t.dir, name), data, 0600)
+}
+
+// replayTransport answers requests from a recording directory, requests that weren't recorded get a 404
+type replayTransport struct {
+	responses map[string]recordedResponse
+}
+
+func newReplayTransport(dir string) (http.RoundTripper, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded responses found in %s", dir)
+	}
+
+	// Files are named in recording order, so a request recorded twice replays its latest response
+	responses := make(map[string]recordedResponse)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var recording recordedResponse
+		if err := json.Unmarshal(data, &recording); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		responses[recordingKey(recording.Method, recording.Endpoint, recording.Query, recording.RequestHash)] = recording
+	}
+	logger.Debugf("Loaded %d recorded responses from %s", len(responses), dir)
+	return &replayTransport{responses: responses}, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hash, err := requestHash(req)
+	if err != nil {
+		return nil, err
+	}
+	key := recordingKey(req.Method, req.URL.Path, req.URL.RawQuery, hash)
+	recording, ok := t.responses[key]
+	if !ok && hash != "" {
+		// Recordings made before request bodies were hashed have a single response per endpoint
+		recording, ok = t.responses[recordingKey(req.Method, req.URL.Path, req.URL.RawQuery, "")]
+	}
+	if !ok {
+		logger.Debugf("No recorded response for %s", key)
+		recording = recordedResponse{
+			Status:  http.StatusNotFound,
+			Headers: http.Header{"Content-Type": {"application/json"}},
+			Body:    json.RawMessage(`{"message":"Not Found in recording"}`),
+		}
+	}
+
+	body := []byte(recording.Body)
+	if recording.Body == nil {
+		body = []byte(recording.BodyText)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recording.Status, http.StatusText(recording.Status)),
+		StatusCode:    recording.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recording.Headers,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
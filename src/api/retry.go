@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	maxRetries     = 4
+	baseRetryDelay = time.Second
+	maxRetryDelay  = 30 * time.Second
+	// Waiting for a primary rate limit reset beyond this is pointless for an interactive run
+	maxRateLimitWait = 5 * time.Minute
+)
+
+// retryTransport retries transient failures with exponential backoff and jitter, honoring the
+// Retry-After and X-RateLimit-Reset headers GitHub sends with rate limited responses
+type retryTransport struct {
+	next http.RoundTripper
+}
+
+func newRetryTransport(next http.RoundTripper) http.RoundTripper {
+	return &retryTransport{next: next}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func logRateLimit(resp *http.Response) {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+	logger.Debugf("Rate limit %s: %s of %s requests remaining", resp.Header.Get("X-RateLimit-Resource"), remaining, resp.Header.Get("X-RateLimit-Limit"))
+}
+
+// isRateLimited detects primary and secondary rate limits, which GitHub reports as 403 or 429
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		return false
+	}
+	if resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+
+	// Secondary rate limits are only recognizable from the message, restore the body for the caller
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+}
+
+// retryDelay returns how long to wait before the next attempt, and false when the response must not be retried
+func retryDelay(resp *http.Response, method string, attempt int, now time.Time) (time.Duration, bool) {
+	rateLimited := isRateLimited(resp)
+	if !rateLimited {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			if !isIdempotent(method) {
+				return 0, false
+			}
+		default:
+			return 0, false
+		}
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		wait := time.Duration(seconds) * time.Second
+		if wait > maxRateLimitWait {
+			logger.Debugf("Retry-After is %s, not waiting", wait)
+			return 0, false
+		}
+		return wait, true
+	}
+	if rateLimited && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			wait := time.Unix(reset, 0).Sub(now) + time.Second
+			if wait > maxRateLimitWait {
+				logger.Debugf("Rate limit resets in %s, not waiting", wait.Round(time.Second))
+				return 0, false
+			}
+			if wait > 0 {
+				return wait, true
+			}
+		}
+	}
+	return backoff(attempt), true
+}
+
+// backoff grows exponentially with the attempt and picks a random delay in its upper half
+func backoff(attempt int) time.Duration {
+	delay := time.Duration(math.Min(float64(baseRetryDelay)*math.Pow(2, float64(attempt)), float64(maxRetryDelay)))
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(req)
+
+		var delay time.Duration
+		retry := false
+		switch {
+		case err != nil:
+			if req.Context().Err() != nil || !isIdempotent(req.Method) {
+				return nil, err
+			}
+			delay, retry = backoff(attempt), true
+			logger.Debugf("Request %s %s failed: %v", req.Method, req.URL.Path, err)
+		default:
+			logRateLimit(resp)
+			delay, retry = retryDelay(resp, req.Method, attempt, time.Now())
+		}
+
+		if !retry || attempt >= maxRetries {
+			return resp, err
+		}
+		if resp != nil {
+			logger.Debugf("Request %s %s returned %s", req.Method, req.URL.Path, resp.Status)
+			resp.Body.Close()
+		}
+		logger.Debugf("Retrying %s %s in %s (attempt %d of %d)", req.Method, req.URL.Path, delay.Round(time.Millisecond), attempt+1, maxRetries)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	resetIn := func(d time.Duration) string {
+		return strconv.FormatInt(now.Add(d).Unix(), 10)
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		method  string
+		headers map[string]string
+		body    string
+		attempt int
+		retry   bool
+		// minWait and maxWait bound the delay, they are equal unless the backoff jitter applies
+		minWait time.Duration
+		maxWait time.Duration
+	}{
+		{
+			name:    "retry after is honored",
+			status:  http.StatusTooManyRequests,
+			method:  http.MethodGet,
+			headers: map[string]string{"Retry-After": "7"},
+			retry:   true,
+			minWait: 7 * time.Second,
+			maxWait: 7 * time.Second,
+		},
+		{
+			name:    "retry after on a forbidden response is a rate limit",
+			status:  http.StatusForbidden,
+			method:  http.MethodPost,
+			headers: map[string]string{"Retry-After": "3"},
+			retry:   true,
+			minWait: 3 * time.Second,
+			maxWait: 3 * time.Second,
+		},
+		{
+			name:    "retry after beyond the maximum wait is not retried",
+			status:  http.StatusTooManyRequests,
+			method:  http.MethodGet,
+			headers: map[string]string{"Retry-After": "3600"},
+		},
+		{
+			name:    "rate limit reset is waited for",
+			status:  http.StatusForbidden,
+			method:  http.MethodGet,
+			headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": resetIn(90 * time.Second)},
+			retry:   true,
+			minWait: 91 * time.Second,
+			maxWait: 91 * time.Second,
+		},
+		{
+			name:    "rate limit reset beyond the maximum wait is not retried",
+			status:  http.StatusForbidden,
+			method:  http.MethodGet,
+			headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": resetIn(time.Hour)},
+		},
+		{
+			name:    "rate limit reset in the past backs off",
+			status:  http.StatusForbidden,
+			method:  http.MethodGet,
+			headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": resetIn(-time.Minute)},
+			retry:   true,
+			minWait: baseRetryDelay / 2,
+			maxWait: baseRetryDelay,
+		},
+		{
+			name:    "secondary rate limit is recognized from the body",
+			status:  http.StatusForbidden,
+			method:  http.MethodGet,
+			body:    `{"message": "You have exceeded a secondary rate limit"}`,
+			attempt: 2,
+			retry:   true,
+			minWait: 2 * time.Second,
+			maxWait: 4 * time.Second,
+		},
+		{
+			name:   "forbidden without a rate limit is not retried",
+			status: http.StatusForbidden,
+			method: http.MethodGet,
+			body:   `{"message": "Resource not accessible by integration"}`,
+		},
+		{
+			name:    "bad gateway is retried for idempotent requests",
+			status:  http.StatusBadGateway,
+			method:  http.MethodGet,
+			attempt: 1,
+			retry:   true,
+			minWait: time.Second,
+			maxWait: 2 * time.Second,
+		},
+		{
+			name:   "bad gateway is not retried for non-idempotent requests",
+			status: http.StatusBadGateway,
+			method: http.MethodPost,
+		},
+		{
+			name:    "backoff is capped",
+			status:  http.StatusServiceUnavailable,
+			method:  http.MethodDelete,
+			attempt: 10,
+			retry:   true,
+			minWait: maxRetryDelay / 2,
+			maxWait: maxRetryDelay,
+		},
+		{
+			name:   "not found is not retried",
+			status: http.StatusNotFound,
+			method: http.MethodGet,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: test.status,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(test.body)),
+			}
+			for key, value := range test.headers {
+				resp.Header.Set(key, value)
+			}
+
+			wait, retry := retryDelay(resp, test.method, test.attempt, now)
+			if retry != test.retry {
+				t.Fatalf("retry = %v, want %v", retry, test.retry)
+			}
+			if wait < test.minWait || wait > test.maxWait {
+				t.Errorf("wait = %s, want between %s and %s", wait, test.minWait, test.maxWait)
+			}
+		})
+	}
+}
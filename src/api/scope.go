@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/cli/go-gh/pkg/api"
+	logger "github.com/sirupsen/logrus"
+)
+
+var scopeTypeFlags = map[string]string{
+	"org":        "orgs",
+	"enterprise": "enterprises",
+}
+
+// explicitScopeTypes holds the scope types given on the command line, they skip auto-detection
+var explicitScopeTypes = make(map[string]string)
+
+// SetScopeType records that scopeName is an "org" or an "enterprise"
+func SetScopeType(scopeName, scopeType string) error {
+	endpoint, ok := scopeTypeFlags[scopeType]
+	if !ok {
+		return fmt.Errorf("invalid scope type %q, use 'org' or 'enterprise'", scopeType)
+	}
+	explicitScopeTypes[strings.ToLower(scopeName)] = endpoint
+	return nil
+}
+
+// ScopeResolutionError explains why a scope could be resolved as neither an organization nor an enterprise
+type ScopeResolutionError struct {
+	Scope         string
+	OrgErr        error
+	EnterpriseErr error
+}
+
+func httpStatus(err error) int {
+	var httpErr api.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	return 0
+}
+
+func describeProbe(kind string, err error) string {
+	if err == nil {
+		return fmt.Sprintf("%s lookup returned no data", kind)
+	}
+
+	var httpErr api.HTTPError
+	if !errors.As(err, &httpErr) {
+		return fmt.Sprintf("%s lookup failed: %v", kind, err)
+	}
+
+	switch httpErr.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Sprintf("no %s found (HTTP 404, this is also returned when the token cannot see it)", kind)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		reason := fmt.Sprintf("access to the %s was denied (HTTP %d: %s)", kind, httpErr.StatusCode, httpErr.Message)
+		if accepted := httpErr.Headers.Get("X-Accepted-OAuth-Scopes"); accepted != "" {
+			reason += fmt.Sprintf(", the endpoint accepts tokens with the scopes: %s", accepted)
+		}
+		return reason
+	default:
+		return fmt.Sprintf("%s lookup failed: %v", kind, err)
+	}
+}
+
+func (e *ScopeResolutionError) Error() string {
+	return fmt.Sprintf("could not resolve scope %s: %s; %s. Use --scope-type to skip auto-detection",
+		e.Scope, describeProbe("organization", e.OrgErr), describeProbe("enterprise", e.EnterpriseErr))
+}
+
+// Unwrap returns the most telling of the two lookup errors, a 404 is expected for one of them
+func (e *ScopeResolutionError) Unwrap() error {
+	if httpStatus(e.OrgErr) != http.StatusNotFound {
+		return e.OrgErr
+	}
+	return e.EnterpriseErr
+}
+
+func scopeCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gh-copilot-insights", "scopes.json"), nil
+}
+
+// scopeCacheKey includes the host, the same name can be a different scope on another host
+func scopeCacheKey(scopeName string) string {
+	return currentHost() + "/" + strings.ToLower(scopeName)
+}
+
+// scopeCacheMutex serializes access to the scope cache, as scopes are resolved concurrently
+var scopeCacheMutex sync.Mutex
+
+func readScopeCache() map[string]string {
+	scopeCacheMutex.Lock()
+	defer scopeCacheMutex.Unlock()
+	return loadScopeCache()
+}
+
+func loadScopeCache() map[string]string {
+	cache := make(map[string]string)
+	path, err := scopeCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		logger.Debugf("Ignoring unreadable scope cache %s: %v", path, err)
+		return make(map[string]string)
+	}
+	return cache
+}
+
+func writeScopeCache(scopeName, scopeType string) {
+	path, err := scopeCachePath()
+	if err != nil {
+		return
+	}
+	scopeCacheMutex.Lock()
+	defer scopeCacheMutex.Unlock()
+	cache := loadScopeCache()
+	cache[scopeCacheKey(scopeName)] = scopeType
+
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+	}
+	if err == nil {
+		// Replace the cache in one step, so other runs never read a partly written file
+		err = writeFileAtomic(path, data)
+	}
+	if err != nil {
+		logger.Debugf("Error writing scope cache %s: %v", path, err)
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// determineEndpoint returns "orgs" or "enterprises" for the scope. Explicit scope types win over the local
+// cache, and only when neither knows the scope are the organization and enterprise endpoints probed.
+func determineEndpoint(ctx context.Context, client api.RESTClient, scope string) (string, error) {
+	if scopeType, ok := explicitScopeTypes[strings.ToLower(scope)]; ok {
+		return scopeType, nil
+	}
+	// Recordings must contain the lookups for replays to resolve the scope on their own
+	if scopeType, ok := readScopeCache()[scopeCacheKey(scope)]; ok && recordDir == "" && replayDir == "" {
+		logger.Debugf("Using cached scope type %s for %s", scopeType, scope)
+		return scopeType, nil
+	}
+	return probeScopeType(ctx, client, scope)
+}
+
+// probeScopeType asks GitHub whether the scope is an organization or an enterprise and caches the answer
+func probeScopeType(ctx context.Context, client api.RESTClient, scope string) (string, error) {
+	// Check if the scope is an organization
+	var orgResponse map[string]interface{}
+	orgErr := get(ctx, client, fmt.Sprintf("orgs/%s", scope), &orgResponse)
+	if orgErr == nil && orgResponse != nil {
+		if replayDir == "" {
+			writeScopeCache(scope, "orgs")
+		}
+		return "orgs", nil
+	}
+
+	// Check if the scope is an enterprise
+	var enterpriseResponse []map[string]interface{}
+	enterpriseErr := get(ctx, client, fmt.Sprintf("enterprises/%s/properties/schema", scope), &enterpriseResponse)
+	if enterpriseErr == nil && enterpriseResponse != nil {
+		if replayDir == "" {
+			writeScopeCache(scope, "enterprises")
+		}
+		return "enterprises", nil
+	}
+
+	err := &ScopeResolutionError{Scope: scope, OrgErr: orgErr, EnterpriseErr: enterpriseErr}
+	logger.Debugf("Invalid scope: %v", err)
+	return "", err
+}
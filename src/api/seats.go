@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+type SeatActivity struct {
+	Login                 string `json:"login"`
+	AssignmentSource      string `json:"assignment_source"`
+	CreatedAt             string `json:"created_at"`
+	LastActivityAt        string `json:"last_activity_at"`
+	LastActivityEditor    string `json:"last_activity_editor"`
+	DaysSinceLastActivity *int   `json:"days_since_last_activity"`
+}
+
+// AssignmentSource describes how the seat was granted, either directly or through a team
+func (s Seat) AssignmentSource() string {
+	if s.AssigningTeam != nil {
+		return fmt.Sprintf("team:%s", s.AssigningTeam.Slug)
+	}
+	return "direct"
+}
+
+// DaysSinceLastActivity returns nil for seats that were never used
+func (s Seat) DaysSinceLastActivity(now time.Time) *int {
+	if s.LastActivityAt == "" {
+		return nil
+	}
+	lastActivity, err := time.Parse(time.RFC3339, s.LastActivityAt)
+	if err != nil {
+		logger.Debugf("Error parsing last activity %q of %s: %v", s.LastActivityAt, s.Assignee.Login, err)
+		return nil
+	}
+	days := int(now.Sub(lastActivity).Hours() / 24)
+	return &days
+}
+
+func FetchCopilotBilling(ctx context.Context, scopeName string) (CopilotBilling, error) {
+	client, err := getRESTClient()
+	if err != nil {
+		logger.Debugf("Error creating REST client: %v", err)
+		return CopilotBilling{}, err
+	}
+
+	scopeType, err := determineEndpoint(ctx, client, scopeName)
+	if err != nil {
+		logger.Debugf("Error determining endpoint for scope %s: %v", scopeName, err)
+		return CopilotBilling{}, err
+	}
+
+	billing, err := fetchBilling(ctx, client, fmt.Sprintf("%s/%s/copilot", scopeType, scopeName))
+	if err != nil {
+		logger.Debugf("Error fetching seats for scope %s: %v", scopeName, err)
+		return CopilotBilling{}, notFound(scopeName, err)
+	}
+	return billing, nil
+}
+
+func FetchCopilotSeats(ctx context.Context, scopeName string) ([]Seat, error) {
+	billing, err := FetchCopilotBilling(ctx, scopeName)
+	if err != nil {
+		return nil, err
+	}
+	return billing.Seats, nil
+}
+
+// GetSeatActivity lists the seats idle for at least inactiveDays, never used seats always qualify.
+// The least recently active seats come first.
+func GetSeatActivity(seats []Seat, inactiveDays int, now time.Time) []SeatActivity {
+	activities := []SeatActivity{}
+	for _, seat := range seats {
+		days := seat.DaysSinceLastActivity(now)
+		if days != nil && *days < inactiveDays {
+			continue
+		}
+		activities = append(activities, SeatActivity{
+			Login:                 seat.Assignee.Login,
+			AssignmentSource:      seat.AssignmentSource(),
+			CreatedAt:             seat.CreatedAt,
+			LastActivityAt:        seat.LastActivityAt,
+			LastActivityEditor:    seat.LastActivityEditor,
+			DaysSinceLastActivity: days,
+		})
+	}
+
+	sort.SliceStable(activities, func(i, j int) bool {
+		a, b := activities[i].DaysSinceLastActivity, activities[j].DaysSinceLastActivity
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return *a > *b
+	})
+	return activities
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// NamedMetric is a metric with the key it has in the JSON output
+type NamedMetric struct {
+	Key    string
+	Metric Metric
+}
+
+func visitMetricMap(prefix string, metrics map[string]Metric, visit func(key string, metric *Metric)) {
+	keys := make([]string, 0, len(metrics))
+	for key := range metrics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		metric := metrics[key]
+		visit(prefix+"."+key, &metric)
+		metrics[key] = metric
+	}
+}
+
+// visitMetrics calls visit with every metric of the insight in the order of the JSON output. Metrics per
+// feature or editor are keyed as <metric>.<feature>, those of a language as languages.<language>.<metric>.
+func (i *Insight) visitMetrics(visit func(key string, metric *Metric)) {
+	visit("seat_utilization_rate", &i.AdoptionUtilization.SeatUtilizationRate)
+	visit("active_vs_engaged_users", &i.AdoptionUtilization.ActiveVsEngagedUsers)
+	visitMetricMap("feature_engagement_rate", i.AdoptionUtilization.FeatureEngagementRate, visit)
+	visit("ide_adoption", &i.AdoptionUtilization.IDEAdoption)
+	visit("dotcom_adoption", &i.AdoptionUtilization.DotcomAdoption)
+	visit("code_acceptance_rate", &i.ProductivityImpact.CodeAcceptanceRate)
+	visit("code_adoption_efficiency", &i.ProductivityImpact.CodeAdoptionEfficiency)
+	visit("ai_chat_engagement", &i.ProductivityImpact.AIChatEngagement)
+	visitMetricMap("editor_acceptance_rate", i.ProductivityImpact.EditorAcceptanceRate, visit)
+	visitMetricMap("editor_line_adoption_efficiency", i.ProductivityImpact.EditorLineAdoptionEfficiency, visit)
+	visit("cost_per_engaged_user", &i.ROICostEfficiency.CostPerEngagedUser)
+	visit("custom_model_efficiency", &i.ROICostEfficiency.CustomModelEfficiency)
+	visit("pr_automation_impact", &i.WorkflowAcceleration.PRAutomationImpact)
+	visit("ai_driven_code_speed", &i.WorkflowAcceleration.AIDrivenCodeSpeed)
+	visit("expansion_potential", &i.StrategicGrowth.ExpansionPotential)
+	visitMetricMap("editor_preference_index", i.StrategicGrowth.EditorPreferenceIndex, visit)
+	for l := range i.Languages {
+		visit("languages."+i.Languages[l].Name+".acceptance_rate", &i.Languages[l].AcceptanceRate)
+		visit("languages."+i.Languages[l].Name+".line_adoption_efficiency", &i.Languages[l].LineAdoptionEfficiency)
+	}
+}
+
+// Metrics lists every metric of the insight in the order of the JSON output
+func (i Insight) Metrics() []NamedMetric {
+	var metrics []NamedMetric
+	i.visitMetrics(func(key string, metric *Metric) {
+		metrics = append(metrics, NamedMetric{Key: key, Metric: *metric})
+	})
+	return metrics
+}
+
+// SeriesPoint holds the metric values of one day or week, keyed as in Insight.Metrics
+type SeriesPoint struct {
+	Period string             `json:"period"`
+	Window DateRange          `json:"window"`
+	Values map[string]float64 `json:"values"`
+}
+
+// InsightSeries shows how the metrics of an insight changed over its window
+type InsightSeries struct {
+	Daily  []SeriesPoint `json:"daily"`
+	Weekly []SeriesPoint `json:"weekly"`
+}
+
+// isoWeek names the ISO week of a date like 2024-W07
+func isoWeek(date string) string {
+	day, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	year, week := day.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// series splits the data into periods and computes the insight of each, seat counts are the current ones
+// for every period as GitHub only reports the current seats
+func (d scopeData) series(period func(date string) string) []SeriesPoint {
+	var periods []string
+	usage := make(map[string][]CopilotUsage)
+	metrics := make(map[string][]CopilotMetrics)
+	for _, m := range d.Metrics {
+		key := period(m.Date)
+		if _, ok := metrics[key]; !ok {
+			periods = append(periods, key)
+		}
+		metrics[key] = append(metrics[key], m)
+	}
+	for _, u := range d.Usage {
+		usage[period(u.Day)] = append(usage[period(u.Day)], u)
+	}
+	sort.Strings(periods)
+
+	points := make([]SeriesPoint, 0, len(periods))
+	for _, key := range periods {
+		insight := getInsights(d.Name, d.Type, usage[key], metrics[key], d.Billing)
+		point := SeriesPoint{Period: key, Window: effectiveWindow(DateRange{}, metrics[key]), Values: make(map[string]float64)}
+		for _, metric := range insight.Metrics() {
+			point.Values[metric.Key] = metric.Metric.Value
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+func (d scopeData) insightSeries() *InsightSeries {
+	return &InsightSeries{
+		Daily:  d.series(func(date string) string { return date }),
+		Weekly: d.series(isoWeek),
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cli/go-gh/pkg/api"
+	logger "github.com/sirupsen/logrus"
+)
+
+// Scope identifies the organization, enterprise, or team whose data is read
+type Scope struct {
+	// Type is either "orgs" or "enterprises"
+	Type string
+	Name string
+	// Team is empty unless the scope is a team of the organization or enterprise
+	Team string
+}
+
+func (s Scope) endpoint() string {
+	if s.Team != "" {
+		return fmt.Sprintf("%s/%s/team/%s/copilot", s.Type, s.Name, s.Team)
+	}
+	return fmt.Sprintf("%s/%s/copilot", s.Type, s.Name)
+}
+
+// DataSource provides the raw Copilot data that insights are computed from
+type DataSource interface {
+	// ScopeType returns "orgs" or "enterprises" for the scope name
+	ScopeType(ctx context.Context, scopeName string) (string, error)
+	Usage(ctx context.Context, scope Scope, window DateRange) ([]CopilotUsage, error)
+	Metrics(ctx context.Context, scope Scope, window DateRange) ([]CopilotMetrics, error)
+	Billing(ctx context.Context, scope Scope) (CopilotBilling, error)
+}
+
+// organizationLister is implemented by data sources that can list the organizations of an enterprise
+type organizationLister interface {
+	Organizations(ctx context.Context, enterprise string) ([]string, error)
+}
+
+// githubSource reads the data from the GitHub REST API
+type githubSource struct {
+	client api.RESTClient
+}
+
+func NewGitHubSource() (DataSource, error) {
+	client, err := getRESTClient()
+	if err != nil {
+		logger.Debugf("Error creating REST client: %v", err)
+		return nil, err
+	}
+	return githubSource{client: client}, nil
+}
+
+func (s githubSource) ScopeType(ctx context.Context, scopeName string) (string, error) {
+	return determineEndpoint(ctx, s.client, scopeName)
+}
+
+func (s githubSource) Usage(ctx context.Context, scope Scope, window DateRange) ([]CopilotUsage, error) {
+	var usage []CopilotUsage
+	err := get(ctx, s.client, fmt.Sprintf("%s/usage%s", scope.endpoint(), window.query()), &usage)
+	return usage, err
+}
+
+func (s githubSource) Metrics(ctx context.Context, scope Scope, window DateRange) ([]CopilotMetrics, error) {
+	var metrics []CopilotMetrics
+	err := get(ctx, s.client, fmt.Sprintf("%s/metrics%s", scope.endpoint(), window.query()), &metrics)
+	return metrics, err
+}
+
+func (s githubSource) Billing(ctx context.Context, scope Scope) (CopilotBilling, error) {
+	if scope.Team != "" {
+		return fetchTeamSeats(ctx, s.client, scope.Type, scope.Name, scope.Team)
+	}
+	return fetchBilling(ctx, s.client, scope.endpoint())
+}
+
+func (s githubSource) Organizations(ctx context.Context, enterprise string) ([]string, error) {
+	return fetchEnterpriseOrganizations(ctx, s.client, enterprise)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// DateRange is an inclusive window of days, either bound may be empty
+type DateRange struct {
+	Since string `json:"since,omitempty"`
+	Until string `json:"until,omitempty"`
+}
+
+func ParseDateRange(since, until string) (DateRange, error) {
+	for _, day := range []string{since, until} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, day); err != nil {
+			return DateRange{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", day)
+		}
+	}
+	if since != "" && until != "" && since > until {
+		return DateRange{}, fmt.Errorf("since %s is after until %s", since, until)
+	}
+	return DateRange{Since: since, Until: until}, nil
+}
+
+func (r DateRange) Contains(day string) bool {
+	// Days are compared as YYYY-MM-DD strings, timestamps are truncated to the day
+	if len(day) > len(dateLayout) {
+		day = day[:len(dateLayout)]
+	}
+	if r.Since != "" && day < r.Since {
+		return false
+	}
+	if r.Until != "" && day > r.Until {
+		return false
+	}
+	return true
+}
+
+func (r DateRange) query() string {
+	values := url.Values{}
+	if r.Since != "" {
+		values.Set("since", r.Since+"T00:00:00Z")
+	}
+	if r.Until != "" {
+		values.Set("until", r.Until+"T23:59:59Z")
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+func filterUsage(usage []CopilotUsage, window DateRange) []CopilotUsage {
+	var filtered []CopilotUsage
+	for _, u := range usage {
+		if window.Contains(u.Day) {
+			filtered = append(filtered, u)
+		}
+	}
+	return filtered
+}
+
+func filterMetrics(metrics []CopilotMetrics, window DateRange) []CopilotMetrics {
+	var filtered []CopilotMetrics
+	for _, m := range metrics {
+		if window.Contains(m.Date) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// effectiveWindow fills the open bounds of the requested window with the first and last day found in the data
+func effectiveWindow(requested DateRange, metrics []CopilotMetrics) DateRange {
+	window := requested
+	for _, m := range metrics {
+		if requested.Since == "" && (window.Since == "" || m.Date < window.Since) {
+			window.Since = m.Date
+		}
+		if requested.Until == "" && (window.Until == "" || m.Date > window.Until) {
+			window.Until = m.Date
+		}
+	}
+	return window
+}
+
+// Previous returns the window of the same length that ends the day before r starts, r must have both bounds
+func (r DateRange) Previous() (DateRange, error) {
+	since, err := time.Parse(dateLayout, r.Since)
+	if err != nil {
+		return DateRange{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", r.Since)
+	}
+	until, err := time.Parse(dateLayout, r.Until)
+	if err != nil {
+		return DateRange{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", r.Until)
+	}
+	days := int(until.Sub(since).Hours()/24) + 1
+	return DateRange{
+		Since: since.AddDate(0, 0, -days).Format(dateLayout),
+		Until: since.AddDate(0, 0, -1).Format(dateLayout),
+	}, nil
+}
@@ -0,0 +1,40 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/chkp-roniz/gh-copilot-insights/src/api"
+)
+
+var marks = map[string]string{
+	api.CheckPassed:  "✓",
+	api.CheckWarning: "!",
+	api.CheckFailed:  "✗",
+	api.CheckSkipped: "-",
+}
+
+func PrintJSON(checks []api.Check) {
+	data, err := json.MarshalIndent(checks, "", "  ")
+	if err != nil {
+		fmt.Printf("Error marshalling JSON: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+func PrintChecklist(checks []api.Check) {
+	for _, check := range checks {
+		fmt.Printf("%s %s\n", marks[check.Status], check.Name)
+		if check.Detail != "" {
+			fmt.Printf("    %s\n", check.Detail)
+		}
+	}
+
+	if api.Healthy(checks) {
+		fmt.Println("\nAll checks passed.")
+	} else {
+		fmt.Println("\nSome checks failed, see the details above.")
+	}
+}
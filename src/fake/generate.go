@@ -0,0 +1,499 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chkp-roniz/gh-copilot-insights/src/api"
+)
+
+const (
+	GrowthFlat     = "flat"
+	GrowthLinear   = "linear"
+	GrowthLogistic = "logistic"
+)
+
+// Config describes the population and behavior of generated users
+type Config struct {
+	Users int
+	Days  int
+	// End is the last generated day
+	End  time.Time
+	Seed int64
+
+	Editors   []string
+	Languages []string
+	// Models lists the completion and chat models, the first is the default model and the others are custom
+	Models []string
+	// Teams that seats are assigned through, users without a team are assigned directly
+	Teams []string
+
+	// AcceptanceRate is the mean share of suggestions users accept, each user's own rate is drawn from a
+	// normal distribution with AcceptanceSpread as standard deviation
+	AcceptanceRate   float64
+	AcceptanceSpread float64
+
+	// Growth is the adoption curve from InitialAdoption on the first day to Adoption on the last day
+	Growth          string
+	InitialAdoption float64
+	Adoption        float64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Users:            50,
+		Days:             28,
+		End:              time.Now(),
+		Seed:             1,
+		Editors:          []string{"vscode", "jetbrains", "neovim", "visualstudio"},
+		Languages:        []string{"go", "python", "typescript", "terraform"},
+		Models:           []string{"default"},
+		AcceptanceRate:   0.3,
+		AcceptanceSpread: 0.08,
+		Growth:           GrowthFlat,
+		InitialAdoption:  0.8,
+		Adoption:         0.8,
+	}
+}
+
+func (c Config) Validate() error {
+	switch {
+	case c.Users <= 0 || c.Days <= 0:
+		return fmt.Errorf("users and days must be positive")
+	case len(c.Editors) == 0 || len(c.Languages) == 0 || len(c.Models) == 0:
+		return fmt.Errorf("at least one editor, language and model is required")
+	case c.AcceptanceRate < 0 || c.AcceptanceRate > 1:
+		return fmt.Errorf("acceptance rate must be between 0 and 1")
+	case c.InitialAdoption < 0 || c.InitialAdoption > 1 || c.Adoption < 0 || c.Adoption > 1:
+		return fmt.Errorf("adoption must be between 0 and 1")
+	}
+	switch c.Growth {
+	case GrowthFlat, GrowthLinear, GrowthLogistic:
+		return nil
+	}
+	return fmt.Errorf("invalid growth curve %q, use '%s', '%s', or '%s'", c.Growth, GrowthFlat, GrowthLinear, GrowthLogistic)
+}
+
+// adoption returns the share of users that adopted Copilot by the given day
+func (c Config) adoption(day int) float64 {
+	progress := 1.0
+	if c.Days > 1 {
+		progress = float64(day) / float64(c.Days-1)
+	}
+	switch c.Growth {
+	case GrowthLinear:
+		return c.InitialAdoption + (c.Adoption-c.InitialAdoption)*progress
+	case GrowthLogistic:
+		return c.InitialAdoption + (c.Adoption-c.InitialAdoption)/(1+math.Exp(-10*(progress-0.5)))
+	default:
+		return c.Adoption
+	}
+}
+
+// Dataset holds the raw API data generated for one scope
+type Dataset struct {
+	Usage   []api.CopilotUsage
+	Metrics []api.CopilotMetrics
+	Billing api.CopilotBilling
+}
+
+type user struct {
+	login      string
+	threshold  float64
+	editor     string
+	languages  []string
+	model      string
+	acceptance float64
+	team       string
+	created    time.Time
+	lastActive time.Time
+}
+
+// counters accumulates the activity of one day for an editor, model and language
+type counters struct {
+	users, suggestions, acceptances, linesSuggested, linesAccepted int
+	chats, chatInsertions, chatCopies, summaries                   int
+}
+
+type key struct {
+	editor, model, language string
+}
+
+func clamp(value, min, max float64) float64 {
+	return math.Max(min, math.Min(max, value))
+}
+
+func pick(random *rand.Rand, values []string) string {
+	return values[random.Intn(len(values))]
+}
+
+// without returns values without any occurrence of value
+func without(values []string, value string) []string {
+	var rest []string
+	for _, v := range values {
+		if v != value {
+			rest = append(rest, v)
+		}
+	}
+	return rest
+}
+
+func newUsers(config Config, random *rand.Rand, scopeName string) []*user {
+	users := make([]*user, config.Users)
+	for i := range users {
+		u := &user{
+			login:      fmt.Sprintf("%s-user-%03d", scopeName, i+1),
+			threshold:  random.Float64(),
+			editor:     pick(random, config.Editors),
+			model:      config.Models[0],
+			acceptance: clamp(random.NormFloat64()*config.AcceptanceSpread+config.AcceptanceRate, 0.02, 0.95),
+			created:    config.End.AddDate(0, 0, -config.Days-random.Intn(180)),
+		}
+		// Users work mostly in one language and sometimes in a second one
+		u.languages = []string{pick(random, config.Languages)}
+		if rest := without(config.Languages, u.languages[0]); random.Intn(3) == 0 && len(rest) > 0 {
+			u.languages = append(u.languages, pick(random, rest))
+		}
+		// A fifth of the users opt into a custom model when there is one
+		if len(config.Models) > 1 && random.Intn(5) == 0 {
+			u.model = pick(random, config.Models[1:])
+		}
+		if len(config.Teams) > 0 && random.Intn(2) == 0 {
+			u.team = pick(random, config.Teams)
+		}
+		users[i] = u
+	}
+	return users
+}
+
+// Generate simulates every user day by day, so the totals, the nested editor, model and language breakdowns
+// and the seats are consistent with each other
+func Generate(config Config, scopeName string) Dataset {
+	hash := fnv.New64a()
+	fmt.Fprint(hash, scopeName)
+	random := rand.New(rand.NewSource(config.Seed ^ int64(hash.Sum64())))
+	users := newUsers(config, random, scopeName)
+
+	var dataset Dataset
+	for day := 0; day < config.Days; day++ {
+		date := config.End.AddDate(0, 0, day-config.Days+1)
+		weekend := date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+		adoption := config.adoption(day)
+
+		completions := make(map[key]*counters)
+		chats := make(map[key]*counters)
+		usage := api.CopilotUsage{Day: date.Format("2006-01-02")}
+		metrics := api.CopilotMetrics{Date: usage.Day}
+		languageUsers := make(map[string]int)
+		dotcomChats := make(map[string]*counters)
+		pullRequests := make(map[string]*counters)
+
+		for _, u := range users {
+			activeChance := 0.85
+			if weekend {
+				activeChance = 0.15
+			}
+			if u.threshold >= adoption || random.Float64() >= activeChance {
+				continue
+			}
+			metrics.TotalActiveUsers++
+			usage.TotalActiveUsers++
+			u.lastActive = date.Add(time.Duration(8+random.Intn(10)) * time.Hour)
+
+			// Some active users only open the IDE without interacting with Copilot
+			if random.Float64() >= 0.9 {
+				continue
+			}
+			metrics.TotalEngagedUsers++
+
+			if random.Float64() < 0.9 {
+				metrics.CopilotIDECodeCompletions.TotalEngagedUsers++
+				for i, language := range u.languages {
+					c := completions[key{u.editor, u.model, language}]
+					if c == nil {
+						c = &counters{}
+						completions[key{u.editor, u.model, language}] = c
+					}
+					suggestions := (10 + random.Intn(60)) / (i + 1)
+					acceptances := int(math.Round(float64(suggestions) * clamp(u.acceptance+random.NormFloat64()*0.05, 0, 1)))
+					lines := suggestions * (1 + random.Intn(4))
+					linesAccepted := int(math.Round(float64(lines) * float64(acceptances) / math.Max(float64(suggestions), 1)))
+					c.users++
+					c.suggestions += suggestions
+					c.acceptances += acceptances
+					c.linesSuggested += lines
+					c.linesAccepted += linesAccepted
+					languageUsers[language]++
+
+					usage.TotalSuggestionsCount += suggestions
+					usage.TotalAcceptancesCount += acceptances
+					usage.TotalLinesSuggested += lines
+					usage.TotalLinesAccepted += linesAccepted
+				}
+			}
+
+			if random.Float64() < 0.4 {
+				c := chats[key{u.editor, u.model, ""}]
+				if c == nil {
+					c = &counters{}
+					chats[key{u.editor, u.model, ""}] = c
+				}
+				turns := 1 + random.Intn(8)
+				insertions := random.Intn(turns + 1)
+				c.users++
+				c.chats += turns
+				c.chatInsertions += insertions
+				c.chatCopies += random.Intn(turns + 1)
+				metrics.CopilotIDEChat.TotalEngagedUsers++
+				usage.TotalActiveChatUsers++
+				usage.TotalChatTurns += turns
+				usage.TotalChatAcceptances += insertions
+			}
+			if random.Float64() < 0.1 {
+				c := dotcomChats[u.model]
+				if c == nil {
+					c = &counters{}
+					dotcomChats[u.model] = c
+				}
+				c.users++
+				c.chats += 1 + random.Intn(5)
+				metrics.CopilotDotcomChat.TotalEngagedUsers++
+			}
+			if random.Float64() < 0.05 {
+				repository := fmt.Sprintf("%s/repo-%d", scopeName, 1+random.Intn(5))
+				c := pullRequests[repository]
+				if c == nil {
+					c = &counters{}
+					pullRequests[repository] = c
+				}
+				c.users++
+				c.summaries += 1 + random.Intn(2)
+				metrics.CopilotDotcomPullRequests.TotalEngagedUsers++
+			}
+		}
+
+		metrics.CopilotIDECodeCompletions.Editors = completionEditors(completions, config.Models[0])
+		metrics.CopilotIDECodeCompletions.Languages = completionLanguages(completions, languageUsers)
+		metrics.CopilotIDEChat.Editors = chatEditors(chats, config.Models[0])
+		metrics.CopilotDotcomChat.Models = dotcomChatModels(dotcomChats, config.Models[0])
+		metrics.CopilotDotcomPullRequests.Repositories = pullRequestRepositories(pullRequests)
+		dataset.Usage = append(dataset.Usage, usage)
+		dataset.Metrics = append(dataset.Metrics, metrics)
+	}
+
+	dataset.Billing = seats(config, users)
+	return dataset
+}
+
+func sortedKeys(m map[key]*counters) []key {
+	keys := make([]key, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.editor != b.editor {
+			return a.editor < b.editor
+		}
+		if a.model != b.model {
+			return a.model < b.model
+		}
+		return a.language < b.language
+	})
+	return keys
+}
+
+// completionEditors builds the editors → models → languages tree of the code completion metrics, every model
+// but defaultModel is a custom one
+func completionEditors(completions map[key]*counters, defaultModel string) []api.EditorMetrics {
+	var editors []api.EditorMetrics
+	for _, k := range sortedKeys(completions) {
+		c := completions[k]
+		if len(editors) == 0 || editors[len(editors)-1].Name != k.editor {
+			editors = append(editors, api.EditorMetrics{Name: k.editor})
+		}
+		editor := &editors[len(editors)-1]
+		if len(editor.Models) == 0 || editor.Models[len(editor.Models)-1].Name != k.model {
+			editor.Models = append(editor.Models, api.ModelMetrics{Name: k.model, IsCustomModel: k.model != defaultModel})
+		}
+		model := &editor.Models[len(editor.Models)-1]
+		model.Languages = append(model.Languages, api.LanguageMetrics{
+			Name:                    k.language,
+			TotalEngagedUsers:       c.users,
+			TotalCodeSuggestions:    c.suggestions,
+			TotalCodeAcceptances:    c.acceptances,
+			TotalCodeLinesSuggested: c.linesSuggested,
+			TotalCodeLinesAccepted:  c.linesAccepted,
+		})
+		// Users of several languages are counted once per language, which overstates the engaged users
+		// of an editor slightly, as the real API does not
+		model.TotalEngagedUsers += c.users
+		editor.TotalEngagedUsers += c.users
+	}
+	return editors
+}
+
+func completionLanguages(completions map[key]*counters, languageUsers map[string]int) []api.LanguageMetrics {
+	var names []string
+	for name := range languageUsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	languages := make([]api.LanguageMetrics, 0, len(names))
+	for _, name := range names {
+		languages = append(languages, api.LanguageMetrics{Name: name, TotalEngagedUsers: languageUsers[name]})
+	}
+	return languages
+}
+
+func chatEditors(chats map[key]*counters, defaultModel string) []api.EditorMetrics {
+	var editors []api.EditorMetrics
+	for _, k := range sortedKeys(chats) {
+		c := chats[k]
+		if len(editors) == 0 || editors[len(editors)-1].Name != k.editor {
+			editors = append(editors, api.EditorMetrics{Name: k.editor})
+		}
+		editor := &editors[len(editors)-1]
+		editor.Models = append(editor.Models, api.ModelMetrics{
+			Name:                     k.model,
+			IsCustomModel:            k.model != defaultModel,
+			TotalEngagedUsers:        c.users,
+			TotalChats:               c.chats,
+			TotalChatInsertionEvents: c.chatInsertions,
+			TotalChatCopyEvents:      c.chatCopies,
+		})
+		editor.TotalEngagedUsers += c.users
+	}
+	return editors
+}
+
+func sortedNames(m map[string]*counters) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func dotcomChatModels(chats map[string]*counters, defaultModel string) []api.ModelMetrics {
+	var models []api.ModelMetrics
+	for _, name := range sortedNames(chats) {
+		c := chats[name]
+		models = append(models, api.ModelMetrics{Name: name, IsCustomModel: name != defaultModel, TotalEngagedUsers: c.users, TotalChats: c.chats})
+	}
+	return models
+}
+
+// pullRequestRepositories reports every PR summary with the default model, as GitHub does
+func pullRequestRepositories(pullRequests map[string]*counters) []api.RepositoryMetrics {
+	var repositories []api.RepositoryMetrics
+	for _, name := range sortedNames(pullRequests) {
+		c := pullRequests[name]
+		repositories = append(repositories, api.RepositoryMetrics{
+			Name:              name,
+			TotalEngagedUsers: c.users,
+			Models:            []api.ModelMetrics{{Name: "default", TotalEngagedUsers: c.users, TotalPRSummariesCreated: c.summaries}},
+		})
+	}
+	return repositories
+}
+
+func seats(config Config, users []*user) api.CopilotBilling {
+	billing := api.CopilotBilling{Total: len(users)}
+	for i, u := range users {
+		seat := api.Seat{
+			Assignee:  api.SeatAssignee{Login: u.login, ID: i + 1, Type: "User"},
+			CreatedAt: u.created.UTC().Format(time.RFC3339),
+			UpdatedAt: u.created.UTC().Format(time.RFC3339),
+			PlanType:  "business",
+		}
+		if u.team != "" {
+			seat.AssigningTeam = &api.SeatAssigningTeam{Name: u.team, Slug: u.team}
+		}
+		if !u.lastActive.IsZero() {
+			seat.LastActivityAt = u.lastActive.UTC().Format(time.RFC3339)
+			seat.LastActivityEditor = fmt.Sprintf("%s/copilot", u.editor)
+		}
+		billing.Seats = append(billing.Seats, seat)
+	}
+	return billing
+}
+
+// WriteDir saves the dataset as usage.json, metrics.json and billing.json, the layout read by --from-dir
+func (d Dataset) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for name, v := range map[string]interface{}{
+		"usage.json":   d.Usage,
+		"metrics.json": d.Metrics,
+		"billing.json": d.Billing,
+	} {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generatedSource generates a dataset for any scope name, the same name always yields the same data
+type generatedSource struct {
+	config   Config
+	mutex    sync.Mutex
+	datasets map[string]Dataset
+}
+
+// NewGeneratedSource generates data with config for every requested scope. Teams get a quarter of the users.
+func NewGeneratedSource(config Config) api.DataSource {
+	return &generatedSource{config: config, datasets: make(map[string]Dataset)}
+}
+
+func (s *generatedSource) dataset(scope api.Scope) Dataset {
+	name := scope.Name
+	config := s.config
+	if scope.Team != "" {
+		name = fmt.Sprintf("%s-%s", scope.Name, scope.Team)
+		config.Users = (config.Users + 3) / 4
+		config.Teams = nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	dataset, ok := s.datasets[name]
+	if !ok {
+		dataset = Generate(config, name)
+		s.datasets[name] = dataset
+	}
+	return dataset
+}
+
+func (s *generatedSource) ScopeType(ctx context.Context, scopeName string) (string, error) {
+	return "orgs", nil
+}
+
+func (s *generatedSource) Usage(ctx context.Context, scope api.Scope, window api.DateRange) ([]api.CopilotUsage, error) {
+	return s.dataset(scope).Usage, nil
+}
+
+func (s *generatedSource) Metrics(ctx context.Context, scope api.Scope, window api.DateRange) ([]api.CopilotMetrics, error) {
+	return s.dataset(scope).Metrics, nil
+}
+
+func (s *generatedSource) Billing(ctx context.Context, scope api.Scope) (api.CopilotBilling, error) {
+	return s.dataset(scope).Billing, nil
+}
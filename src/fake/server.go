@@ -0,0 +1,233 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chkp-roniz/gh-copilot-insights/src/api"
+	logger "github.com/sirupsen/logrus"
+)
+
+// Server imitates the GitHub Copilot REST API on top of a data source
+type Server struct {
+	source      api.DataSource
+	enterprises map[string]bool
+	orgs        []string
+}
+
+// NewServer serves source, the given enterprises are reported as enterprises and every other name as an
+// organization. orgs are listed as the organizations of every enterprise.
+func NewServer(source api.DataSource, enterprises, orgs []string) *Server {
+	s := &Server{source: source, enterprises: make(map[string]bool), orgs: orgs}
+	for _, enterprise := range enterprises {
+		s.enterprises[strings.ToLower(enterprise)] = true
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Debugf("Error writing response: %v", err)
+	}
+}
+
+func notFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger.Debugf("%s %s", r.Method, r.URL)
+
+	if r.Method == http.MethodPost && r.URL.Path == "/api/graphql" {
+		s.serveOrganizations(w)
+		return
+	}
+	if r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, "/api/v3/") {
+		notFound(w)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v3/"), "/"), "/")
+	if len(parts) < 2 || (parts[0] != "orgs" && parts[0] != "enterprises") {
+		notFound(w)
+		return
+	}
+	scope := api.Scope{Type: parts[0], Name: parts[1]}
+	if (scope.Type == "enterprises") != s.enterprises[strings.ToLower(scope.Name)] {
+		notFound(w)
+		return
+	}
+	rest := parts[2:]
+
+	switch {
+	case len(rest) == 0 && scope.Type == "orgs":
+		writeJSON(w, http.StatusOK, map[string]string{"login": scope.Name})
+	case strings.Join(rest, "/") == "properties/schema":
+		writeJSON(w, http.StatusOK, []interface{}{})
+	case len(rest) == 2 && rest[0] == "teams" && scope.Type == "orgs":
+		s.serveTeam(w, r, api.Scope{Type: scope.Type, Name: scope.Name, Team: rest[1]})
+	case len(rest) == 3 && rest[0] == "teams" && (rest[2] == "members" || rest[2] == "memberships"):
+		s.serveMemberships(w, r, api.Scope{Type: scope.Type, Name: scope.Name, Team: rest[1]})
+	case len(rest) == 4 && rest[0] == "team" && rest[2] == "copilot":
+		scope.Team = rest[1]
+		s.serveCopilot(w, r, scope, rest[3:])
+	case len(rest) >= 2 && rest[0] == "copilot":
+		s.serveCopilot(w, r, scope, rest[1:])
+	default:
+		notFound(w)
+	}
+}
+
+func (s *Server) serveCopilot(w http.ResponseWriter, r *http.Request, scope api.Scope, rest []string) {
+	window := api.DateRange{Since: dateOf(r.URL.Query().Get("since")), Until: dateOf(r.URL.Query().Get("until"))}
+
+	switch strings.Join(rest, "/") {
+	case "usage":
+		usage, err := s.source.Usage(r.Context(), scope, window)
+		s.respond(w, filter(len(usage), window, func(i int) string { return usage[i].Day }, func(i int) interface{} { return usage[i] }), err)
+	case "metrics":
+		metrics, err := s.source.Metrics(r.Context(), scope, window)
+		s.respond(w, filter(len(metrics), window, func(i int) string { return metrics[i].Date }, func(i int) interface{} { return metrics[i] }), err)
+	case "billing/seats":
+		if scope.Team != "" {
+			notFound(w)
+			return
+		}
+		billing, err := s.source.Billing(r.Context(), scope)
+		if err != nil {
+			s.respond(w, nil, err)
+			return
+		}
+		seats := paginate(w, r, len(billing.Seats))
+		s.respond(w, api.CopilotBilling{Total: billing.Total, Seats: billing.Seats[seats.start:seats.end]}, nil)
+	default:
+		notFound(w)
+	}
+}
+
+func (s *Server) serveTeam(w http.ResponseWriter, r *http.Request, scope api.Scope) {
+	billing, err := s.source.Billing(r.Context(), scope)
+	s.respond(w, map[string]interface{}{"slug": scope.Team, "members_count": billing.Total}, err)
+}
+
+func (s *Server) serveMemberships(w http.ResponseWriter, r *http.Request, scope api.Scope) {
+	billing, err := s.source.Billing(r.Context(), scope)
+	if err != nil {
+		s.respond(w, nil, err)
+		return
+	}
+	// Members hold the first seats of the organization or enterprise, so the team's seats match its data
+	parent, err := s.source.Billing(r.Context(), api.Scope{Type: scope.Type, Name: scope.Name})
+	if err != nil {
+		s.respond(w, nil, err)
+		return
+	}
+	members := make([]api.SeatAssignee, 0, billing.Total)
+	for i := 0; i < billing.Total; i++ {
+		member := api.SeatAssignee{Login: fmt.Sprintf("%s-member-%03d", scope.Team, i+1), ID: i + 1, Type: "User"}
+		if i < len(parent.Seats) {
+			member = parent.Seats[i].Assignee
+		}
+		members = append(members, member)
+	}
+	page := paginate(w, r, len(members))
+	s.respond(w, members[page.start:page.end], nil)
+}
+
+func (s *Server) serveOrganizations(w http.ResponseWriter) {
+	nodes := make([]map[string]string, 0, len(s.orgs))
+	for _, org := range s.orgs {
+		nodes = append(nodes, map[string]string{"login": org})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"enterprise": map[string]interface{}{
+				"organizations": map[string]interface{}{
+					"nodes":    nodes,
+					"pageInfo": map[string]interface{}{"hasNextPage": false, "endCursor": ""},
+				},
+			},
+		},
+	})
+}
+
+func (s *Server) respond(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		logger.Debugf("Error reading data: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+// dateOf truncates an ISO 8601 timestamp to its day
+func dateOf(timestamp string) string {
+	if len(timestamp) > len("2006-01-02") {
+		return timestamp[:len("2006-01-02")]
+	}
+	return timestamp
+}
+
+func filter(n int, window api.DateRange, day func(int) string, item func(int) interface{}) []interface{} {
+	filtered := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		if window.Contains(day(i)) {
+			filtered = append(filtered, item(i))
+		}
+	}
+	return filtered
+}
+
+type pageRange struct {
+	start, end int
+}
+
+// paginate selects the requested page of n items and sets the Link header to the next page
+func paginate(w http.ResponseWriter, r *http.Request, n int) pageRange {
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 50
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	start := (page - 1) * perPage
+	if start > n {
+		start = n
+	}
+	end := start + perPage
+	if end > n {
+		end = n
+	}
+	if end < n {
+		next := *r.URL
+		query := next.Query()
+		query.Set("page", strconv.Itoa(page+1))
+		query.Set("per_page", strconv.Itoa(perPage))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s>; rel="next"`, r.Host, next.RequestURI()))
+	}
+	return pageRange{start: start, end: end}
+}
+
+// ListenAndServe serves until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	server := &http.Server{Addr: addr, Handler: s}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	err := server.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package seats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chkp-roniz/gh-copilot-insights/src/api"
+	"github.com/olekukonko/tablewriter"
+)
+
+// ReadAssignmentList reads a CSV or plain text file whose first column holds either a login or a team
+// written as team:<slug> or <org>/<slug>, where org must be the organization the seats are assigned in. Blank
+// lines, comments and a header row are ignored.
+func ReadAssignmentList(path, organization string) ([]api.SeatAssignment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	var assignments []api.SeatAssignment
+	seen := make(map[string]bool)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entry := strings.TrimSpace(record[0])
+		switch strings.ToLower(entry) {
+		case "", "login", "user", "username", "team":
+			continue
+		}
+
+		var assignment api.SeatAssignment
+		switch {
+		case strings.HasPrefix(entry, "team:"):
+			assignment.Team = strings.TrimPrefix(entry, "team:")
+		case strings.Contains(entry, "/"):
+			i := strings.LastIndex(entry, "/")
+			if org := entry[:i]; !strings.EqualFold(org, organization) {
+				return nil, fmt.Errorf("line %d: team %s belongs to %s, not %s", line, entry, org, organization)
+			}
+			assignment.Team = entry[i+1:]
+		default:
+			assignment.Login = strings.TrimPrefix(entry, "@")
+		}
+		if assignment.Login == "" && assignment.Team == "" {
+			return nil, fmt.Errorf("line %d: empty team slug", line)
+		}
+
+		key := strings.ToLower(assignment.String())
+		if !seen[key] {
+			seen[key] = true
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments, nil
+}
+
+func PrintAssignmentJSON(plan api.AssignmentPlan) {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		fmt.Printf("Error marshalling JSON: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+func PrintAssignmentTable(plan api.AssignmentPlan, seatPrice float64) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"User / Team", "Status", "New Seats", "Error"})
+
+	for _, result := range plan.Results {
+		table.Append([]string{result.SeatAssignment.String(), result.Status, strconv.Itoa(result.NewSeats), result.Error})
+	}
+	table.Render()
+
+	delta := plan.ProjectedSeats - plan.CurrentSeats
+	fmt.Printf("Seats: %d -> %d (%+d)\n", plan.CurrentSeats, plan.ProjectedSeats, delta)
+	fmt.Printf("Monthly cost: $%.2f -> $%.2f (%+.2f)\n", float64(plan.CurrentSeats)*seatPrice, float64(plan.ProjectedSeats)*seatPrice, float64(delta)*seatPrice)
+}
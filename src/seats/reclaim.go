@@ -0,0 +1,99 @@
+package seats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chkp-roniz/gh-copilot-insights/src/api"
+	"github.com/olekukonko/tablewriter"
+)
+
+func PrintPlanJSON(plan api.ReclaimPlan) {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		fmt.Printf("Error marshalling JSON: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+func PrintPlanTable(plan api.ReclaimPlan) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Login", "Reason", "Assigned Via", "Days Inactive", "Action"})
+
+	for _, candidate := range plan.Remove {
+		table.Append([]string{candidate.Login, candidate.Reason, candidate.AssignmentSource, formatDays(candidate.DaysSinceLastActivity), "remove"})
+	}
+	for _, candidate := range plan.Skipped {
+		table.Append([]string{candidate.Login, candidate.Reason, candidate.AssignmentSource, formatDays(candidate.DaysSinceLastActivity), "skip: " + candidate.SkipReason})
+	}
+
+	table.SetFooter([]string{"", "", "", "Seats to remove", fmt.Sprintf("%d", len(plan.Remove))})
+	table.Render()
+}
+
+// ReadAllowList reads one login per line, blank lines and lines starting with # are ignored
+func ReadAllowList(path string) (map[string]bool, error) {
+	allowList := make(map[string]bool)
+	if path == "" {
+		return allowList, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		allowList[strings.ToLower(strings.TrimPrefix(line, "@"))] = true
+	}
+	return allowList, scanner.Err()
+}
+
+type auditEntry struct {
+	Timestamp    string `json:"timestamp"`
+	Organization string `json:"organization"`
+	Login        string `json:"login"`
+	Reason       string `json:"reason"`
+	Result       string `json:"result"`
+	// Requested and Cancelled count the seats of the whole request, GitHub doesn't report which were cancelled
+	Requested int `json:"seats_requested"`
+	Cancelled int `json:"seats_cancelled"`
+}
+
+// WriteAuditLog appends one JSON line per removed seat to the audit log at path, along with how many seats
+// GitHub reported as cancelled
+func WriteAuditLog(path string, plan api.ReclaimPlan, result string, cancelled int, now time.Time) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, candidate := range plan.Remove {
+		err := encoder.Encode(auditEntry{
+			Timestamp:    now.UTC().Format(time.RFC3339),
+			Organization: plan.Organization,
+			Login:        candidate.Login,
+			Reason:       candidate.Reason,
+			Result:       result,
+			Requested:    len(plan.Remove),
+			Cancelled:    cancelled,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package seats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/chkp-roniz/gh-copilot-insights/src/api"
+	"github.com/olekukonko/tablewriter"
+)
+
+func PrintJSON(activities []api.SeatActivity) {
+	data, err := json.MarshalIndent(activities, "", "  ")
+	if err != nil {
+		fmt.Printf("Error marshalling JSON: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+func formatDays(days *int) string {
+	if days == nil {
+		return "never"
+	}
+	return strconv.Itoa(*days)
+}
+
+func PrintTable(activities []api.SeatActivity) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Login", "Assigned Via", "Last Activity", "Editor", "Days Inactive"})
+
+	for _, activity := range activities {
+		table.Append([]string{activity.Login, activity.AssignmentSource, activity.LastActivityAt, activity.LastActivityEditor, formatDays(activity.DaysSinceLastActivity)})
+	}
+
+	table.SetFooter([]string{"", "", "", "Seats", strconv.Itoa(len(activities))})
+	table.Render()
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/chkp-roniz/gh-copilot-insights/src/api"
 	"github.com/olekukonko/tablewriter"
@@ -23,86 +25,290 @@ func toPercentage(value float64) string {
 	return fmt.Sprintf("%.0f%%", math.Min(value, 1)*100)
 }
 
-func printMetric(category, displayName, description string, value float64) {
+// formatChange shows how a value moved since the previous window, in percentage points and relative to the
+// previous value, empty when there is nothing to compare with
+func formatChange(value float64, previous *float64) string {
+	if previous == nil {
+		return ""
+	}
+	// Compare the values as they are shown, which caps them at 100%
+	value, before := math.Min(value, 1), math.Min(*previous, 1)
+	delta := math.Round((value - before) * 100)
+	change := fmt.Sprintf("→ %.0f pp", math.Abs(delta))
+	switch {
+	case delta > 0:
+		change = fmt.Sprintf("↑ %+.0f pp", delta)
+	case delta < 0:
+		change = fmt.Sprintf("↓ %+.0f pp", delta)
+	}
+	// A value unchanged as shown has no relative change either, which rounding could print as -0%
+	if before != 0 && delta != 0 {
+		change += fmt.Sprintf(" (%+.0f%%)", math.Round((value-before)/before*100))
+	}
+	return fmt.Sprintf("%s, was %s", change, toPercentage(before))
+}
+
+func formatValue(value float64, previous *float64) string {
+	if change := formatChange(value, previous); change != "" {
+		return fmt.Sprintf("%s %s", toPercentage(value), change)
+	}
+	return toPercentage(value)
+}
+
+// sortedNames lists the features or editors of a metric map in alphabetical order
+func sortedNames(metrics map[string]api.Metric) []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printMetric(category, displayName, description string, value float64, previous *float64) {
 	fmt.Printf("## %s\n\n", category)
-	fmt.Printf("**%s**: %s\n", displayName, toPercentage(value))
+	fmt.Printf("**%s**: %s\n", displayName, formatValue(value, previous))
 	fmt.Printf("%s\n\n", description)
 }
 
-func appendMetric(table *tablewriter.Table, category, displayName, description string, value float64) {
-	table.Append([]string{category, displayName, toPercentage(value), description})
+func appendMetric(table *tablewriter.Table, category, displayName, description string, value float64, previous *float64) {
+	table.Append([]string{category, displayName, formatValue(value, previous), description})
+}
+
+func formatWindow(window api.DateRange) string {
+	if window.Since == "" && window.Until == "" {
+		return "no data"
+	}
+	return fmt.Sprintf("%s to %s", window.Since, window.Until)
+}
+
+func formatSource(source string) string {
+	if source == api.CompletionsFromUsage {
+		return "legacy usage API"
+	}
+	return "metrics API"
 }
 
 func PrintSummary(insights []api.Insight, extended bool) {
 	for _, insight := range insights {
 		if len(insights) > 0 {
 			fmt.Printf("# GitHub Copilot Insights for %s (%s)\n\n", insight.ScopeName, insight.ScopeType)
+			fmt.Printf("Window: %s\n", formatWindow(insight.Window))
+			if insight.PreviousWindow != nil {
+				fmt.Printf("Compared with: %s\n", formatWindow(*insight.PreviousWindow))
+			}
+			fmt.Printf("Completions source: %s\n\n", formatSource(insight.CompletionsSource))
 		}
-		printMetric(insight.AdoptionUtilization.SeatUtilizationRate.Category, insight.AdoptionUtilization.SeatUtilizationRate.DisplayName, insight.AdoptionUtilization.SeatUtilizationRate.Description, insight.AdoptionUtilization.SeatUtilizationRate.Value)
-		printMetric(insight.AdoptionUtilization.ActiveVsEngagedUsers.Category, insight.AdoptionUtilization.ActiveVsEngagedUsers.DisplayName, insight.AdoptionUtilization.ActiveVsEngagedUsers.Description, insight.AdoptionUtilization.ActiveVsEngagedUsers.Value)
+		printMetric(insight.AdoptionUtilization.SeatUtilizationRate.Category, insight.AdoptionUtilization.SeatUtilizationRate.DisplayName, insight.AdoptionUtilization.SeatUtilizationRate.Description, insight.AdoptionUtilization.SeatUtilizationRate.Value, insight.AdoptionUtilization.SeatUtilizationRate.Previous)
+		printMetric(insight.AdoptionUtilization.ActiveVsEngagedUsers.Category, insight.AdoptionUtilization.ActiveVsEngagedUsers.DisplayName, insight.AdoptionUtilization.ActiveVsEngagedUsers.Description, insight.AdoptionUtilization.ActiveVsEngagedUsers.Value, insight.AdoptionUtilization.ActiveVsEngagedUsers.Previous)
 		if extended {
-			for feature, metric := range insight.AdoptionUtilization.FeatureEngagementRate {
-				printMetric(metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, feature), metric.Description, metric.Value)
+			for _, feature := range sortedNames(insight.AdoptionUtilization.FeatureEngagementRate) {
+				metric := insight.AdoptionUtilization.FeatureEngagementRate[feature]
+				printMetric(metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, feature), metric.Description, metric.Value, metric.Previous)
 			}
 		}
-		printMetric(insight.AdoptionUtilization.IDEAdoption.Category, insight.AdoptionUtilization.IDEAdoption.DisplayName, insight.AdoptionUtilization.IDEAdoption.Description, insight.AdoptionUtilization.IDEAdoption.Value)
-		printMetric(insight.AdoptionUtilization.DotcomAdoption.Category, insight.AdoptionUtilization.DotcomAdoption.DisplayName, insight.AdoptionUtilization.DotcomAdoption.Description, insight.AdoptionUtilization.DotcomAdoption.Value)
+		printMetric(insight.AdoptionUtilization.IDEAdoption.Category, insight.AdoptionUtilization.IDEAdoption.DisplayName, insight.AdoptionUtilization.IDEAdoption.Description, insight.AdoptionUtilization.IDEAdoption.Value, insight.AdoptionUtilization.IDEAdoption.Previous)
+		printMetric(insight.AdoptionUtilization.DotcomAdoption.Category, insight.AdoptionUtilization.DotcomAdoption.DisplayName, insight.AdoptionUtilization.DotcomAdoption.Description, insight.AdoptionUtilization.DotcomAdoption.Value, insight.AdoptionUtilization.DotcomAdoption.Previous)
 
-		printMetric(insight.ProductivityImpact.CodeAcceptanceRate.Category, insight.ProductivityImpact.CodeAcceptanceRate.DisplayName, insight.ProductivityImpact.CodeAcceptanceRate.Description, insight.ProductivityImpact.CodeAcceptanceRate.Value)
-		printMetric(insight.ProductivityImpact.CodeAdoptionEfficiency.Category, insight.ProductivityImpact.CodeAdoptionEfficiency.DisplayName, insight.ProductivityImpact.CodeAdoptionEfficiency.Description, insight.ProductivityImpact.CodeAdoptionEfficiency.Value)
-		printMetric(insight.ProductivityImpact.AIChatEngagement.Category, insight.ProductivityImpact.AIChatEngagement.DisplayName, insight.ProductivityImpact.AIChatEngagement.Description, insight.ProductivityImpact.AIChatEngagement.Value)
+		printMetric(insight.ProductivityImpact.CodeAcceptanceRate.Category, insight.ProductivityImpact.CodeAcceptanceRate.DisplayName, insight.ProductivityImpact.CodeAcceptanceRate.Description, insight.ProductivityImpact.CodeAcceptanceRate.Value, insight.ProductivityImpact.CodeAcceptanceRate.Previous)
+		printMetric(insight.ProductivityImpact.CodeAdoptionEfficiency.Category, insight.ProductivityImpact.CodeAdoptionEfficiency.DisplayName, insight.ProductivityImpact.CodeAdoptionEfficiency.Description, insight.ProductivityImpact.CodeAdoptionEfficiency.Value, insight.ProductivityImpact.CodeAdoptionEfficiency.Previous)
+		printMetric(insight.ProductivityImpact.AIChatEngagement.Category, insight.ProductivityImpact.AIChatEngagement.DisplayName, insight.ProductivityImpact.AIChatEngagement.Description, insight.ProductivityImpact.AIChatEngagement.Value, insight.ProductivityImpact.AIChatEngagement.Previous)
+		if extended {
+			for _, editor := range sortedNames(insight.ProductivityImpact.EditorAcceptanceRate) {
+				metric := insight.ProductivityImpact.EditorAcceptanceRate[editor]
+				printMetric(metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, editor), metric.Description, metric.Value, metric.Previous)
+			}
+			for _, editor := range sortedNames(insight.ProductivityImpact.EditorLineAdoptionEfficiency) {
+				metric := insight.ProductivityImpact.EditorLineAdoptionEfficiency[editor]
+				printMetric(metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, editor), metric.Description, metric.Value, metric.Previous)
+			}
+		}
 
-		printMetric(insight.ROICostEfficiency.CostPerEngagedUser.Category, insight.ROICostEfficiency.CostPerEngagedUser.DisplayName, insight.ROICostEfficiency.CostPerEngagedUser.Description, insight.ROICostEfficiency.CostPerEngagedUser.Value)
-		printMetric(insight.ROICostEfficiency.CustomModelEfficiency.Category, insight.ROICostEfficiency.CustomModelEfficiency.DisplayName, insight.ROICostEfficiency.CustomModelEfficiency.Description, insight.ROICostEfficiency.CustomModelEfficiency.Value)
+		printMetric(insight.ROICostEfficiency.CostPerEngagedUser.Category, insight.ROICostEfficiency.CostPerEngagedUser.DisplayName, insight.ROICostEfficiency.CostPerEngagedUser.Description, insight.ROICostEfficiency.CostPerEngagedUser.Value, insight.ROICostEfficiency.CostPerEngagedUser.Previous)
+		printMetric(insight.ROICostEfficiency.CustomModelEfficiency.Category, insight.ROICostEfficiency.CustomModelEfficiency.DisplayName, insight.ROICostEfficiency.CustomModelEfficiency.Description, insight.ROICostEfficiency.CustomModelEfficiency.Value, insight.ROICostEfficiency.CustomModelEfficiency.Previous)
 
-		// printMetric(insight.WorkflowAcceleration.PRAutomationImpact.Category, insight.WorkflowAcceleration.PRAutomationImpact.DisplayName, insight.WorkflowAcceleration.PRAutomationImpact.Description, insight.WorkflowAcceleration.PRAutomationImpact.Value)
-		// printMetric(insight.WorkflowAcceleration.AIDrivenCodeSpeed.Category, insight.WorkflowAcceleration.AIDrivenCodeSpeed.DisplayName, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Description, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Value)
+		// printMetric(insight.WorkflowAcceleration.PRAutomationImpact.Category, insight.WorkflowAcceleration.PRAutomationImpact.DisplayName, insight.WorkflowAcceleration.PRAutomationImpact.Description, insight.WorkflowAcceleration.PRAutomationImpact.Value, insight.WorkflowAcceleration.PRAutomationImpact.Previous)
+		// printMetric(insight.WorkflowAcceleration.AIDrivenCodeSpeed.Category, insight.WorkflowAcceleration.AIDrivenCodeSpeed.DisplayName, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Description, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Value, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Previous)
 
 		if extended {
 			fmt.Printf("## 📣 %s\n\n", insight.StrategicGrowth.ExpansionPotential.Category)
-			// printMetric(insight.StrategicGrowth.ExpansionPotential.Category, insight.StrategicGrowth.ExpansionPotential.DisplayName, insight.StrategicGrowth.ExpansionPotential.Description, insight.StrategicGrowth.ExpansionPotential.Value)
-			for editor, metric := range insight.StrategicGrowth.EditorPreferenceIndex {
-				printMetric(metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, editor), metric.Description, metric.Value)
+			// printMetric(insight.StrategicGrowth.ExpansionPotential.Category, insight.StrategicGrowth.ExpansionPotential.DisplayName, insight.StrategicGrowth.ExpansionPotential.Description, insight.StrategicGrowth.ExpansionPotential.Value, insight.StrategicGrowth.ExpansionPotential.Previous)
+			for _, editor := range sortedNames(insight.StrategicGrowth.EditorPreferenceIndex) {
+				metric := insight.StrategicGrowth.EditorPreferenceIndex[editor]
+				printMetric(metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, editor), metric.Description, metric.Value, metric.Previous)
 			}
 		}
+
+		if insight.Languages != nil {
+			printLanguagesSummary(insight.Languages)
+		}
+		if insight.Series != nil {
+			printSeriesSummary("Daily", insight, insight.Series.Daily)
+			printSeriesSummary("Weekly", insight, insight.Series.Weekly)
+		}
 	}
 }
 
-func PrintTable(insights []api.Insight, extended bool) {
+var languageHeader = []string{"Language", "Engaged Users / Day", "Suggestions", "Acceptance Rate", "Line Adoption Efficiency"}
+
+func languageRow(language api.LanguageInsight) []string {
+	return []string{
+		language.Name,
+		fmt.Sprintf("%.1f", language.EngagedUsers),
+		fmt.Sprintf("%d", language.Suggestions),
+		formatValue(language.AcceptanceRate.Value, language.AcceptanceRate.Previous),
+		formatValue(language.LineAdoptionEfficiency.Value, language.LineAdoptionEfficiency.Previous),
+	}
+}
+
+func printLanguagesSummary(languages []api.LanguageInsight) {
+	fmt.Printf("## 🌐 Languages\n\n")
+	fmt.Printf("| %s |\n", strings.Join(languageHeader, " | "))
+	fmt.Printf("|%s\n", strings.Repeat("---|", len(languageHeader)))
+	for _, language := range languages {
+		fmt.Printf("| %s |\n", strings.Join(languageRow(language), " | "))
+	}
+	fmt.Println()
+}
+
+func printLanguagesTable(languages []api.LanguageInsight) {
+	fmt.Printf("\n## 🌐 Languages\n\n")
 	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(languageHeader)
+	for _, language := range languages {
+		table.Append(languageRow(language))
+	}
+	table.Render()
+}
+
+// seriesKeys are the metrics shown per period in the summary and table, the JSON output has all of them
+var seriesKeys = []string{"seat_utilization_rate", "active_vs_engaged_users", "code_acceptance_rate", "code_adoption_efficiency", "ai_chat_engagement"}
+
+func seriesHeader(insight api.Insight) []string {
+	names := make(map[string]string)
+	for _, metric := range insight.Metrics() {
+		names[metric.Key] = metric.Metric.DisplayName
+	}
+	header := []string{"Period"}
+	for _, key := range seriesKeys {
+		header = append(header, names[key])
+	}
+	return header
+}
+
+func seriesRow(point api.SeriesPoint) []string {
+	row := []string{point.Period}
+	for _, key := range seriesKeys {
+		row = append(row, toPercentage(point.Values[key]))
+	}
+	return row
+}
+
+func printSeriesSummary(title string, insight api.Insight, points []api.SeriesPoint) {
+	fmt.Printf("## 📈 %s Trend\n\n", title)
+	header := seriesHeader(insight)
+	fmt.Printf("| %s |\n", strings.Join(header, " | "))
+	fmt.Printf("|%s\n", strings.Repeat("---|", len(header)))
+	for _, point := range points {
+		fmt.Printf("| %s |\n", strings.Join(seriesRow(point), " | "))
+	}
+	fmt.Println()
+}
+
+func printSeriesTable(title string, insight api.Insight, points []api.SeriesPoint) {
+	fmt.Printf("\n## 📈 %s Trend\n\n", title)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(seriesHeader(insight))
+	for _, point := range points {
+		table.Append(seriesRow(point))
+	}
+	table.Render()
+}
+
+func PrintTable(insights []api.Insight, extended bool) {
 	headers := []string{"Category", "Metric", "Value", "Description"}
 	if extended {
 		headers = append(headers, "Extended Info")
 	}
-	table.SetHeader(headers)
 
-	for _, insight := range insights {
+	// Each scope gets its own table below its heading
+	for i, insight := range insights {
+		if i > 0 {
+			fmt.Println()
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(headers)
+
 		if len(insights) > 0 {
 			fmt.Printf("# GitHub Copilot Insights for %s (%s)\n\n", insight.ScopeName, insight.ScopeType)
+			fmt.Printf("Window: %s\n", formatWindow(insight.Window))
+			if insight.PreviousWindow != nil {
+				fmt.Printf("Compared with: %s\n", formatWindow(*insight.PreviousWindow))
+			}
+			fmt.Printf("Completions source: %s\n\n", formatSource(insight.CompletionsSource))
 		}
-		appendMetric(table, "🚀 "+insight.AdoptionUtilization.SeatUtilizationRate.Category, insight.AdoptionUtilization.SeatUtilizationRate.DisplayName, insight.AdoptionUtilization.SeatUtilizationRate.Description, insight.AdoptionUtilization.SeatUtilizationRate.Value)
-		appendMetric(table, "🚀 "+insight.AdoptionUtilization.ActiveVsEngagedUsers.Category, insight.AdoptionUtilization.ActiveVsEngagedUsers.DisplayName, insight.AdoptionUtilization.ActiveVsEngagedUsers.Description, insight.AdoptionUtilization.ActiveVsEngagedUsers.Value)
-		appendMetric(table, "🚀 "+insight.AdoptionUtilization.IDEAdoption.Category, insight.AdoptionUtilization.IDEAdoption.DisplayName, insight.AdoptionUtilization.IDEAdoption.Description, insight.AdoptionUtilization.IDEAdoption.Value)
-		appendMetric(table, "🚀 "+insight.AdoptionUtilization.DotcomAdoption.Category, insight.AdoptionUtilization.DotcomAdoption.DisplayName, insight.AdoptionUtilization.DotcomAdoption.Description, insight.AdoptionUtilization.DotcomAdoption.Value)
+		appendMetric(table, "🚀 "+insight.AdoptionUtilization.SeatUtilizationRate.Category, insight.AdoptionUtilization.SeatUtilizationRate.DisplayName, insight.AdoptionUtilization.SeatUtilizationRate.Description, insight.AdoptionUtilization.SeatUtilizationRate.Value, insight.AdoptionUtilization.SeatUtilizationRate.Previous)
+		appendMetric(table, "🚀 "+insight.AdoptionUtilization.ActiveVsEngagedUsers.Category, insight.AdoptionUtilization.ActiveVsEngagedUsers.DisplayName, insight.AdoptionUtilization.ActiveVsEngagedUsers.Description, insight.AdoptionUtilization.ActiveVsEngagedUsers.Value, insight.AdoptionUtilization.ActiveVsEngagedUsers.Previous)
+		appendMetric(table, "🚀 "+insight.AdoptionUtilization.IDEAdoption.Category, insight.AdoptionUtilization.IDEAdoption.DisplayName, insight.AdoptionUtilization.IDEAdoption.Description, insight.AdoptionUtilization.IDEAdoption.Value, insight.AdoptionUtilization.IDEAdoption.Previous)
+		appendMetric(table, "🚀 "+insight.AdoptionUtilization.DotcomAdoption.Category, insight.AdoptionUtilization.DotcomAdoption.DisplayName, insight.AdoptionUtilization.DotcomAdoption.Description, insight.AdoptionUtilization.DotcomAdoption.Value, insight.AdoptionUtilization.DotcomAdoption.Previous)
 		if extended {
-			for feature, metric := range insight.AdoptionUtilization.FeatureEngagementRate {
-				appendMetric(table, "🚀 "+metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, feature), metric.Description, metric.Value)
+			for _, feature := range sortedNames(insight.AdoptionUtilization.FeatureEngagementRate) {
+				metric := insight.AdoptionUtilization.FeatureEngagementRate[feature]
+				appendMetric(table, "🚀 "+metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, feature), metric.Description, metric.Value, metric.Previous)
 			}
 		}
-		appendMetric(table, "🤖 "+insight.ProductivityImpact.CodeAcceptanceRate.Category, insight.ProductivityImpact.CodeAcceptanceRate.DisplayName, insight.ProductivityImpact.CodeAcceptanceRate.Description, insight.ProductivityImpact.CodeAcceptanceRate.Value)
-		appendMetric(table, "🤖 "+insight.ProductivityImpact.CodeAdoptionEfficiency.Category, insight.ProductivityImpact.CodeAdoptionEfficiency.DisplayName, insight.ProductivityImpact.CodeAdoptionEfficiency.Description, insight.ProductivityImpact.CodeAdoptionEfficiency.Value)
-		appendMetric(table, "🤖 "+insight.ProductivityImpact.AIChatEngagement.Category, insight.ProductivityImpact.AIChatEngagement.DisplayName, insight.ProductivityImpact.AIChatEngagement.Description, insight.ProductivityImpact.AIChatEngagement.Value)
-		appendMetric(table, "💰 "+insight.ROICostEfficiency.CostPerEngagedUser.Category, insight.ROICostEfficiency.CostPerEngagedUser.DisplayName, insight.ROICostEfficiency.CostPerEngagedUser.Description, insight.ROICostEfficiency.CostPerEngagedUser.Value)
-		appendMetric(table, "💰 "+insight.ROICostEfficiency.CustomModelEfficiency.Category, insight.ROICostEfficiency.CustomModelEfficiency.DisplayName, insight.ROICostEfficiency.CustomModelEfficiency.Description, insight.ROICostEfficiency.CustomModelEfficiency.Value)
-		appendMetric(table, "⚡ "+insight.WorkflowAcceleration.PRAutomationImpact.Category, insight.WorkflowAcceleration.PRAutomationImpact.DisplayName, insight.WorkflowAcceleration.PRAutomationImpact.Description, insight.WorkflowAcceleration.PRAutomationImpact.Value)
-		// appendMetric(table, "⚡ "+insight.WorkflowAcceleration.AIDrivenCodeSpeed.Category, insight.WorkflowAcceleration.AIDrivenCodeSpeed.DisplayName, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Description, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Value)
-		// appendMetric(table, "📣 "+insight.StrategicGrowth.ExpansionPotential.Category, insight.StrategicGrowth.ExpansionPotential.DisplayName, insight.StrategicGrowth.ExpansionPotential.Description, insight.StrategicGrowth.ExpansionPotential.Value)
+		appendMetric(table, "🤖 "+insight.ProductivityImpact.CodeAcceptanceRate.Category, insight.ProductivityImpact.CodeAcceptanceRate.DisplayName, insight.ProductivityImpact.CodeAcceptanceRate.Description, insight.ProductivityImpact.CodeAcceptanceRate.Value, insight.ProductivityImpact.CodeAcceptanceRate.Previous)
+		appendMetric(table, "🤖 "+insight.ProductivityImpact.CodeAdoptionEfficiency.Category, insight.ProductivityImpact.CodeAdoptionEfficiency.DisplayName, insight.ProductivityImpact.CodeAdoptionEfficiency.Description, insight.ProductivityImpact.CodeAdoptionEfficiency.Value, insight.ProductivityImpact.CodeAdoptionEfficiency.Previous)
+		appendMetric(table, "🤖 "+insight.ProductivityImpact.AIChatEngagement.Category, insight.ProductivityImpact.AIChatEngagement.DisplayName, insight.ProductivityImpact.AIChatEngagement.Description, insight.ProductivityImpact.AIChatEngagement.Value, insight.ProductivityImpact.AIChatEngagement.Previous)
 		if extended {
-			for editor, metric := range insight.StrategicGrowth.EditorPreferenceIndex {
-				appendMetric(table, "📣 "+metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, editor), metric.Description, metric.Value)
+			for _, editor := range sortedNames(insight.ProductivityImpact.EditorAcceptanceRate) {
+				metric := insight.ProductivityImpact.EditorAcceptanceRate[editor]
+				appendMetric(table, "🤖 "+metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, editor), metric.Description, metric.Value, metric.Previous)
+			}
+			for _, editor := range sortedNames(insight.ProductivityImpact.EditorLineAdoptionEfficiency) {
+				metric := insight.ProductivityImpact.EditorLineAdoptionEfficiency[editor]
+				appendMetric(table, "🤖 "+metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, editor), metric.Description, metric.Value, metric.Previous)
 			}
 		}
+		appendMetric(table, "💰 "+insight.ROICostEfficiency.CostPerEngagedUser.Category, insight.ROICostEfficiency.CostPerEngagedUser.DisplayName, insight.ROICostEfficiency.CostPerEngagedUser.Description, insight.ROICostEfficiency.CostPerEngagedUser.Value, insight.ROICostEfficiency.CostPerEngagedUser.Previous)
+		appendMetric(table, "💰 "+insight.ROICostEfficiency.CustomModelEfficiency.Category, insight.ROICostEfficiency.CustomModelEfficiency.DisplayName, insight.ROICostEfficiency.CustomModelEfficiency.Description, insight.ROICostEfficiency.CustomModelEfficiency.Value, insight.ROICostEfficiency.CustomModelEfficiency.Previous)
+		appendMetric(table, "⚡ "+insight.WorkflowAcceleration.PRAutomationImpact.Category, insight.WorkflowAcceleration.PRAutomationImpact.DisplayName, insight.WorkflowAcceleration.PRAutomationImpact.Description, insight.WorkflowAcceleration.PRAutomationImpact.Value, insight.WorkflowAcceleration.PRAutomationImpact.Previous)
+		// appendMetric(table, "⚡ "+insight.WorkflowAcceleration.AIDrivenCodeSpeed.Category, insight.WorkflowAcceleration.AIDrivenCodeSpeed.DisplayName, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Description, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Value, insight.WorkflowAcceleration.AIDrivenCodeSpeed.Previous)
+		// appendMetric(table, "📣 "+insight.StrategicGrowth.ExpansionPotential.Category, insight.StrategicGrowth.ExpansionPotential.DisplayName, insight.StrategicGrowth.ExpansionPotential.Description, insight.StrategicGrowth.ExpansionPotential.Value, insight.StrategicGrowth.ExpansionPotential.Previous)
+		if extended {
+			for _, editor := range sortedNames(insight.StrategicGrowth.EditorPreferenceIndex) {
+				metric := insight.StrategicGrowth.EditorPreferenceIndex[editor]
+				appendMetric(table, "📣 "+metric.Category, fmt.Sprintf("%s (%s)", metric.DisplayName, editor), metric.Description, metric.Value, metric.Previous)
+			}
+		}
+
+		table.Render()
+
+		if insight.Languages != nil {
+			printLanguagesTable(insight.Languages)
+		}
+		if insight.Series != nil {
+			printSeriesTable("Daily", insight, insight.Series.Daily)
+			printSeriesTable("Weekly", insight, insight.Series.Weekly)
+		}
+	}
+}
+
+// PrintRanking lists the insights in their given order, with the values that explain their rank
+func PrintRanking(insights []api.Insight) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Rank", "Scope", "Seat Utilization Rate", "Active vs. Engaged Users", "Code Acceptance Rate"})
+
+	for i, insight := range insights {
+		table.Append([]string{
+			fmt.Sprintf("%d", i+1),
+			insight.ScopeName,
+			toPercentage(insight.AdoptionUtilization.SeatUtilizationRate.Value),
+			toPercentage(insight.AdoptionUtilization.ActiveVsEngagedUsers.Value),
+			toPercentage(insight.ProductivityImpact.CodeAcceptanceRate.Value),
+		})
 	}
 
+	fmt.Printf("# Ranking by Seat Utilization Rate\n\n")
 	table.Render()
 }